@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+const wordSize = 64
+
+// Bloom concurrency-safe bloom filter of strings, which could be rebuilt without stopping lookups
+type Bloom struct {
+	bits      atomic.Pointer[[]uint64]
+	next      atomic.Pointer[[]uint64]
+	m         uint64
+	k         uint64
+	items     atomic.Int64
+	nextItems atomic.Int64
+	rebuild   sync.Mutex
+}
+
+// NewBloom return filter sized for expected number of items and false positive rate
+func NewBloom(expectedItems int, fpRate float64) *Bloom {
+	n := math.Max(float64(expectedItems), 1)
+	m := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(math.Round(m/n*math.Ln2), 1)
+
+	words := (uint64(m) + wordSize - 1) / wordSize
+
+	b := &Bloom{
+		m: words * wordSize,
+		k: uint64(k),
+	}
+
+	bits := make([]uint64, words)
+	b.bits.Store(&bits)
+
+	return b
+}
+
+// Add add value to filter
+func (b *Bloom) Add(value string) {
+	h1, h2 := fnvHashes(value)
+
+	// next is loaded first, so value added during rebuild is never lost by swap of filters
+	if next := b.next.Load(); next != nil && b.set(*next, h1, h2) {
+		b.nextItems.Add(1)
+	}
+
+	if b.set(*b.bits.Load(), h1, h2) {
+		b.items.Add(1)
+	}
+}
+
+// MayContain return false if value was never added to filter
+func (b *Bloom) MayContain(value string) bool {
+	h1, h2 := fnvHashes(value)
+	bits := *b.bits.Load()
+
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		if atomic.LoadUint64(&bits[pos/wordSize])&(uint64(1)<<(pos%wordSize)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Rebuild replace content of filter by values passed to add by fill, values added concurrently are kept,
+// filter is not changed when fill return error
+func (b *Bloom) Rebuild(fill func(add func(value string)) error) error {
+	b.rebuild.Lock()
+	defer b.rebuild.Unlock()
+
+	bits := make([]uint64, b.m/wordSize)
+
+	b.nextItems.Store(0)
+	b.next.Store(&bits)
+
+	err := fill(func(value string) {
+		h1, h2 := fnvHashes(value)
+		if b.set(bits, h1, h2) {
+			b.nextItems.Add(1)
+		}
+	})
+	if err == nil {
+		b.bits.Store(&bits)
+		b.items.Store(b.nextItems.Load())
+	}
+
+	b.next.Store(nil)
+
+	return err
+}
+
+// Size return size of filter in bits
+func (b *Bloom) Size() uint64 {
+	return b.m
+}
+
+// Items return approximate number of added items
+func (b *Bloom) Items() int64 {
+	return b.items.Load()
+}
+
+// FalsePositiveRate return estimated false positive rate for current number of items
+func (b *Bloom) FalsePositiveRate() float64 {
+	k := float64(b.k)
+
+	return math.Pow(1-math.Exp(-k*float64(b.items.Load())/float64(b.m)), k)
+}
+
+// RegisterMetrics report filter size, items and false positive rate as observable metrics
+func (b *Bloom) RegisterMetrics(meter metric.Meter, name string) error {
+	size, err := meter.Int64ObservableGauge(name + "_size_bits")
+	if err != nil {
+		return err
+	}
+
+	items, err := meter.Int64ObservableGauge(name + "_items")
+	if err != nil {
+		return err
+	}
+
+	fpRate, err := meter.Float64ObservableGauge(name + "_false_positive_rate")
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(size, int64(b.m)) //nolint:gosec
+		o.ObserveInt64(items, b.items.Load())
+		o.ObserveFloat64(fpRate, b.FalsePositiveRate())
+
+		return nil
+	}, size, items, fpRate)
+
+	return err
+}
+
+// set set bits of value and return true if any bit was not set before
+func (b *Bloom) set(bits []uint64, h1, h2 uint64) bool {
+	var changed bool
+
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		mask := uint64(1) << (pos % wordSize)
+
+		if atomic.OrUint64(&bits[pos/wordSize], mask)&mask == 0 {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func fnvHashes(value string) (uint64, uint64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+	h1 := h.Sum64()
+
+	// splitmix64 finalizer derive second independent hash
+	h2 := h1 + 0x9e3779b97f4a7c15
+	h2 = (h2 ^ (h2 >> 30)) * 0xbf58476d1ce4e5b9
+	h2 = (h2 ^ (h2 >> 27)) * 0x94d049bb133111eb
+	h2 ^= h2 >> 31
+
+	return h1, h2 | 1 // odd step visit different positions
+}
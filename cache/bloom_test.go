@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestBloomAddMayContain(t *testing.T) {
+	b := NewBloom(100, 0.01)
+
+	testutils.Equal(t, b.MayContain("a"), false)
+
+	b.Add("a")
+	b.Add("a")
+
+	testutils.Equal(t, b.MayContain("a"), true)
+	testutils.Equal(t, b.MayContain("b"), false)
+	testutils.Equal(t, b.Items(), int64(1))
+}
+
+func TestBloomFalsePositiveRate(t *testing.T) {
+	const (
+		n      = 10000
+		probes = 100000
+		fpRate = 0.01
+	)
+
+	b := NewBloom(n, fpRate)
+
+	for i := range n {
+		b.Add("id-" + strconv.Itoa(i))
+	}
+
+	// no false negatives
+	for i := range n {
+		testutils.Equal(t, b.MayContain("id-"+strconv.Itoa(i)), true)
+	}
+
+	var positives int
+
+	for i := range probes {
+		if b.MayContain("other-" + strconv.Itoa(i)) {
+			positives++
+		}
+	}
+
+	measured := float64(positives) / probes
+	t.Logf("measured false positive rate %f, estimated %f", measured, b.FalsePositiveRate())
+
+	testutils.Equal(t, measured < 2*fpRate, true)
+	testutils.Equal(t, b.FalsePositiveRate() < 2*fpRate, true)
+}
+
+func TestBloomRebuild(t *testing.T) {
+	b := NewBloom(100, 0.01)
+
+	b.Add("removed")
+	b.Add("kept")
+
+	err := b.Rebuild(func(add func(value string)) error {
+		add("kept")
+		// value added concurrently during rebuild must survive swap of filters
+		b.Add("created")
+
+		return nil
+	})
+	testutils.Equal(t, err, nil)
+
+	testutils.Equal(t, b.MayContain("removed"), false)
+	testutils.Equal(t, b.MayContain("kept"), true)
+	testutils.Equal(t, b.MayContain("created"), true)
+	testutils.Equal(t, b.Items(), int64(2))
+
+	errFill := errors.New("fill failed")
+
+	err = b.Rebuild(func(add func(value string)) error {
+		return errFill
+	})
+	testutils.Equal(t, err, errFill)
+	testutils.Equal(t, b.MayContain("kept"), true)
+
+	b.Add("after")
+	testutils.Equal(t, b.MayContain("after"), true)
+}
@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU bounded least recently used cache with ttl, safe for concurrent use
+type LRU[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	items   map[K]*list.Element
+	order   *list.List
+	hits    atomic.Int64
+	misses  atomic.Int64
+	evicted atomic.Int64
+	version atomic.Uint64
+}
+
+// NewLRU return new cache, zero size disable cache, zero ttl keep entries until evicted
+func NewLRU[K comparable, V any](size int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[K]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Get return value and true if key exists and not expired
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var empty V
+
+	if c.size <= 0 {
+		c.misses.Add(1)
+		return empty, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return empty, false
+	}
+
+	e := el.Value.(*entry[K, V])
+	if c.ttl > 0 && time.Now().After(e.expiresAt) {
+		c.remove(el)
+		c.misses.Add(1)
+
+		return empty, false
+	}
+
+	c.order.MoveToFront(el)
+	c.hits.Add(1)
+
+	return e.value, true
+}
+
+// Set add or replace value, least recently used entry is evicted when cache is full
+func (c *LRU[K, V]) Set(key K, value V) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+}
+
+// Version return counter of deletions, value loaded after reading version is set by SetIfVersion
+func (c *LRU[K, V]) Version() uint64 {
+	return c.version.Load()
+}
+
+// SetIfVersion add or replace value only when nothing was deleted since version was read,
+// so value loaded before concurrent deletion is not put back, it return false when value is not set
+func (c *LRU[K, V]) SetIfVersion(key K, value V, version uint64) bool {
+	if c.size <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version.Load() != version {
+		return false
+	}
+
+	c.set(key, value)
+
+	return true
+}
+
+func (c *LRU[K, V]) set(key K, value V) {
+	expiresAt := time.Now().Add(c.ttl)
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		c.evicted.Add(1)
+	}
+}
+
+// Delete remove keys from cache
+func (c *LRU[K, V]) Delete(keys ...K) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// key could be loaded right now, so version is changed even when key is not cached
+	c.version.Add(1)
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+// Purge remove all entries
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version.Add(1)
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+// Len return number of entries
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Stats return hits, misses and evictions counters
+func (c *LRU[K, V]) Stats() (hits, misses, evicted int64) {
+	return c.hits.Load(), c.misses.Load(), c.evicted.Load()
+}
+
+// RegisterMetrics report cache counters as observable metrics with given name prefix
+func (c *LRU[K, V]) RegisterMetrics(meter metric.Meter, name string) error {
+	hits, err := meter.Int64ObservableCounter(name + "_hits")
+	if err != nil {
+		return err
+	}
+
+	misses, err := meter.Int64ObservableCounter(name + "_misses")
+	if err != nil {
+		return err
+	}
+
+	size, err := meter.Int64ObservableGauge(name + "_size")
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(hits, c.hits.Load())
+		o.ObserveInt64(misses, c.misses.Load())
+		o.ObserveInt64(size, int64(c.Len()))
+
+		return nil
+	}, hits, misses, size)
+
+	return err
+}
+
+func (c *LRU[K, V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestLRUGetSet(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+
+	_, ok := c.Get("a")
+	testutils.Equal(t, ok, false)
+
+	c.Set("a", 1)
+	c.Set("a", 2)
+
+	v, ok := c.Get("a")
+	testutils.Equal(t, ok, true)
+	testutils.Equal(t, v, 2)
+	testutils.Equal(t, c.Len(), 1)
+
+	hits, misses, evicted := c.Stats()
+	testutils.Equal(t, hits, int64(1))
+	testutils.Equal(t, misses, int64(1))
+	testutils.Equal(t, evicted, int64(0))
+}
+
+func TestLRUEviction(t *testing.T) {
+	c := NewLRU[string, int](3, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	// a become most recently used, so b is the oldest one
+	_, ok := c.Get("a")
+	testutils.Equal(t, ok, true)
+
+	c.Set("d", 4)
+	c.Set("e", 5)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": false, "d": true, "e": true} {
+		_, ok := c.Get(key)
+		testutils.Equal(t, ok, want)
+	}
+
+	_, _, evicted := c.Stats()
+	testutils.Equal(t, evicted, int64(2))
+	testutils.Equal(t, c.Len(), 3)
+}
+
+func TestLRUTTL(t *testing.T) {
+	c := NewLRU[string, int](10, 100*time.Millisecond)
+
+	c.Set("a", 1)
+	time.Sleep(50 * time.Millisecond)
+	c.Set("b", 2)
+
+	_, ok := c.Get("a")
+	testutils.Equal(t, ok, true)
+
+	time.Sleep(70 * time.Millisecond)
+
+	// ttl is counted from set, not from last get
+	_, ok = c.Get("a")
+	testutils.Equal(t, ok, false)
+
+	_, ok = c.Get("b")
+	testutils.Equal(t, ok, true)
+
+	time.Sleep(70 * time.Millisecond)
+
+	_, ok = c.Get("b")
+	testutils.Equal(t, ok, false)
+	testutils.Equal(t, c.Len(), 0)
+
+	hits, misses, _ := c.Stats()
+	testutils.Equal(t, hits, int64(2))
+	testutils.Equal(t, misses, int64(2))
+}
+
+func TestLRUDelete(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Delete("a", "c", "unknown")
+
+	_, ok := c.Get("a")
+	testutils.Equal(t, ok, false)
+
+	_, ok = c.Get("b")
+	testutils.Equal(t, ok, true)
+	testutils.Equal(t, c.Len(), 1)
+
+	c.Purge()
+	testutils.Equal(t, c.Len(), 0)
+}
+
+func TestLRUSetIfVersion(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+
+	version := c.Version()
+	testutils.Equal(t, c.SetIfVersion("a", 1, version), true)
+
+	// value loaded before concurrent deletion of any key must not be set
+	version = c.Version()
+	c.Delete("a")
+	testutils.Equal(t, c.SetIfVersion("a", 1, version), false)
+
+	_, ok := c.Get("a")
+	testutils.Equal(t, ok, false)
+
+	version = c.Version()
+	c.Purge()
+	testutils.Equal(t, c.SetIfVersion("a", 1, version), false)
+}
+
+func TestLRUDisabled(t *testing.T) {
+	c := NewLRU[string, int](0, 0)
+
+	c.Set("a", 1)
+	testutils.Equal(t, c.SetIfVersion("a", 1, c.Version()), false)
+
+	_, ok := c.Get("a")
+	testutils.Equal(t, ok, false)
+	testutils.Equal(t, c.Len(), 0)
+
+	_, misses, _ := c.Stats()
+	testutils.Equal(t, misses, int64(1))
+}
@@ -3,44 +3,298 @@ package main
 import (
 	"context"
 	"log/slog"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
 
 	_ "github.com/InsideGallery/core/fastlog/handlers/stderr"
 
+	"github.com/InsideGallery/brf.im/cache"
+	"github.com/InsideGallery/brf.im/events"
+	"github.com/InsideGallery/brf.im/geoip"
 	"github.com/InsideGallery/brf.im/handler"
+	"github.com/InsideGallery/brf.im/shorter"
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
 	"github.com/InsideGallery/core/app"
 	"github.com/InsideGallery/core/db/mongodb"
+	"github.com/InsideGallery/core/errors"
 	"github.com/InsideGallery/core/fastlog/metrics"
+	"github.com/InsideGallery/core/oslistener"
+	"github.com/InsideGallery/core/queue/nats/client"
 	"github.com/InsideGallery/core/server/instance"
 	"github.com/InsideGallery/core/server/profiler"
 )
 
+var ErrUnknownStorageDriver error = errors.New("unknown storage driver")
+
+const (
+	StorageDriverMongo  = "mongo"
+	StorageDriverBolt   = "bolt"
+	StorageDriverMemory = "memory"
+
+	defaultStoragePath = "brfim.db"
+
+	defaultRedirectCacheSize = 10000
+	defaultRedirectCacheTTL  = time.Minute
+
+	defaultNegativeCacheSize  = 10000
+	defaultNegativeCacheTTL   = 10 * time.Second
+	defaultBloomExpectedItems = 1000000
+	defaultBloomFPRate        = 0.01
+	defaultBloomRebuild       = 10 * time.Minute
+
+	defaultExpiredPurgeInterval = time.Hour
+	defaultExpiredPurgeGrace    = 7 * 24 * time.Hour
+)
+
 func main() {
 	ctx := context.Background()
 
 	app.WebMain(ctx, ":8080", "brf.im", func(
 		ctx context.Context,
 		app *fiber.App,
-		_ *metrics.OTLPMetric,
+		met *metrics.OTLPMetric,
 	) error {
-		mongoClient, err := mongodb.Default()
+		// invalid deployment default would fail every create, so fail fast instead
+		err := shorter.GetGeneratorConfigFromEnv().Validate()
+		if err != nil {
+			return err
+		}
+
+		store, err := newStore(ctx, app)
+		if err != nil {
+			return err
+		}
+
+		cachedStore := shorter.NewCachedStore(store, cache.NewLRU[string, shorter.ShortURLModel](
+			getEnvInt("REDIRECT_CACHE_SIZE", defaultRedirectCacheSize),
+			getEnvDuration("REDIRECT_CACHE_TTL", defaultRedirectCacheTTL),
+		))
+
+		err = cachedStore.Redirects().RegisterMetrics(met.GetMetric(), "redirect_cache")
+		if err != nil {
+			return err
+		}
+
+		linkEvents, err := subscribeLinkEvents(ctx, app, cachedStore)
+		if err != nil {
+			return err
+		}
+
+		driver := os.Getenv("STORAGE_DRIVER")
+		sharedStorage := driver != StorageDriverBolt && driver != StorageDriverMemory
+
+		err = setupNegativeLookup(ctx, met, cachedStore, linkEvents || !sharedStorage)
 		if err != nil {
 			return err
 		}
 
-		hl, err := handler.NewHandler(ctx, app, mongoClient)
+		hl, err := handler.NewHandler(ctx, app, cachedStore)
 		if err != nil {
 			return err
 		}
 
+		resolver, err := geoip.NewIPResolverFromEnv()
+		if err != nil {
+			return err
+		}
+
+		hl.WithIPResolver(resolver)
+
+		// other drivers are not shared, so there is single instance and in-memory limits are enough
+		if mongoStore, ok := store.(*shorter.MongoStore); ok {
+			hl.WithLimiterStorage(mongoStore.LimiterStorage(ctx))
+		}
+
+		err = setupGeoIP(ctx, app, hl)
+		if err != nil {
+			return err
+		}
+
+		go shorter.RunPurgeExpired(
+			ctx,
+			cachedStore,
+			getEnvDuration("EXPIRED_PURGE_INTERVAL", defaultExpiredPurgeInterval),
+			getEnvDuration("EXPIRED_PURGE_GRACE", defaultExpiredPurgeGrace),
+		)
+
+		slog.Info("Instance ready", "id", instance.GetShortInstanceID())
+
+		return hl.Run()
+	})
+}
+
+// newStore return store selected by STORAGE_DRIVER (mongo by default)
+func newStore(ctx context.Context, app *fiber.App) (shorter.Store, error) {
+	driver := os.Getenv("STORAGE_DRIVER")
+
+	switch driver {
+	case "", StorageDriverMongo:
+		mongoClient, err := mongodb.Default()
+		if err != nil {
+			return nil, err
+		}
+
 		profiler.AddHealthCheck(func() error {
 			return mongoClient.Ping(ctx, readpref.SecondaryPreferred())
 		})
 
-		slog.Info("Instance ready", "id", instance.GetShortInstanceID())
+		store := shorter.NewMongoStore(mongoClient)
 
-		return hl.Run()
+		err = store.EnsureIndexes(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return store, nil
+	case StorageDriverBolt:
+		path := os.Getenv("STORAGE_PATH")
+		if path == "" {
+			path = defaultStoragePath
+		}
+
+		store, err := shorter.NewBoltStore(path)
+		if err != nil {
+			return nil, err
+		}
+
+		app.Hooks().OnShutdown(store.Close)
+
+		return store, nil
+	case StorageDriverMemory:
+		return shorter.NewMemoryStore(), nil
+	}
+
+	return nil, errors.Wrapf(ErrUnknownStorageDriver, "driver %s", driver)
+}
+
+// subscribeLinkEvents share link mutations between instances over NATS, when NATS_ADDR is set
+func subscribeLinkEvents(ctx context.Context, app *fiber.App, store *shorter.CachedStore) (bool, error) {
+	if os.Getenv("NATS_ADDR") == "" {
+		return false, nil
+	}
+
+	natsClient, err := client.Default(ctx, slog.Default())
+	if err != nil {
+		return false, err
+	}
+
+	app.Hooks().OnShutdown(natsClient.Close)
+
+	subject := os.Getenv("LINK_EVENTS_SUBJECT")
+	if subject == "" {
+		subject = events.DefaultSubject
+	}
+
+	bus := events.NewNATSBus(natsClient.Conn(), subject, instance.GetInstanceID())
+
+	_, err = bus.Subscribe(store.HandleEvent)
+	if err != nil {
+		return false, err
+	}
+
+	store.WithPublisher(bus)
+
+	return true, nil
+}
+
+// setupNegativeLookup enable negative cache and bloom filter of existing short ids,
+// bloom filter require to know about all created links, so it is enabled only when
+// link events are shared between instances or storage is not shared (not mongo)
+func setupNegativeLookup(ctx context.Context, met *metrics.OTLPMetric, store *shorter.CachedStore, bloom bool) error {
+	negative := cache.NewLRU[string, struct{}](
+		getEnvInt("NEGATIVE_CACHE_SIZE", defaultNegativeCacheSize),
+		getEnvDuration("NEGATIVE_CACHE_TTL", defaultNegativeCacheTTL),
+	)
+
+	err := negative.RegisterMetrics(met.GetMetric(), "negative_cache")
+	if err != nil {
+		return err
+	}
+
+	expectedItems := getEnvInt("BLOOM_EXPECTED_ITEMS", defaultBloomExpectedItems)
+	if !bloom || expectedItems <= 0 {
+		slog.Warn("Bloom filter of short ids is disabled")
+		store.WithNegativeLookup(nil, negative)
+
+		return nil
+	}
+
+	fpRate, err := strconv.ParseFloat(os.Getenv("BLOOM_FP_RATE"), 64)
+	if err != nil || fpRate <= 0 || fpRate >= 1 {
+		fpRate = defaultBloomFPRate
+	}
+
+	filter := cache.NewBloom(expectedItems, fpRate)
+	store.WithNegativeLookup(filter, negative)
+
+	err = store.WarmUp(ctx)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Bloom filter of short ids is ready", "items", filter.Items(), "size", filter.Size())
+
+	// create events could be lost, so filter is rebuilt from storage
+	go shorter.RunBloomRebuild(ctx, store, getEnvDuration("BLOOM_REBUILD_INTERVAL", defaultBloomRebuild))
+
+	return filter.RegisterMetrics(met.GetMetric(), "short_ids_bloom")
+}
+
+// setupGeoIP enable country overrides when GEOIP_DB is set, database is reloaded on SIGHUP
+func setupGeoIP(ctx context.Context, app *fiber.App, hl *handler.Handler) error {
+	path := os.Getenv("GEOIP_DB")
+	if path == "" {
+		return nil
+	}
+
+	db, err := geoip.Open(path)
+	if err != nil {
+		return err
+	}
+
+	app.Hooks().OnShutdown(db.Close)
+
+	hl.WithGeoIP(db)
+
+	listener := handler.NewSignalListener()
+	listener.Add(syscall.SIGHUP, func() {
+		err := db.Reload()
+		if err != nil {
+			slog.Error("Error reloading geoip database", "err", err, "path", path)
+			return
+		}
+
+		slog.Info("Geoip database reloaded", "path", path)
 	})
+	oslistener.Start(ctx, listener)
+
+	// web main stop server on SIGHUP, it is registered before listen, so it is reset there
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		oslistener.Get().Reset(syscall.SIGHUP)
+		return nil
+	})
+
+	return nil
+}
+
+func getEnvInt(name string, defaultValue int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return defaultValue
+	}
+
+	return v
+}
+
+func getEnvDuration(name string, defaultValue time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return defaultValue
+	}
+
+	return v
 }
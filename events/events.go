@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Link mutation event types
+const (
+	TypeCreate      = "create"
+	TypeUpdate      = "update"
+	TypeDelete      = "delete"
+	TypeOwnerRemove = "owner_remove"
+
+	DefaultSubject = "brfim.links"
+)
+
+// LinkEvent describe mutation of one or more short urls
+type LinkEvent struct {
+	Type     string   `json:"type"`
+	ShortIDs []string `json:"shortIDs"`
+	Owner    string   `json:"owner,omitempty"`
+	Instance string   `json:"instance"`
+}
+
+// Publisher publish link events to other instances
+type Publisher interface {
+	Publish(ctx context.Context, event LinkEvent) error
+}
+
+// NATSBus publish and receive link events over NATS subject
+type NATSBus struct {
+	conn     *nats.Conn
+	subject  string
+	instance string
+}
+
+// NewNATSBus return new bus, events published by given instance are not delivered back to it
+func NewNATSBus(conn *nats.Conn, subject, instance string) *NATSBus {
+	return &NATSBus{
+		conn:     conn,
+		subject:  subject,
+		instance: instance,
+	}
+}
+
+func (b *NATSBus) Publish(_ context.Context, event LinkEvent) error {
+	event.Instance = b.instance
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return b.conn.Publish(b.subject, data)
+}
+
+// Subscribe call fn for every event published by other instances
+func (b *NATSBus) Subscribe(fn func(event LinkEvent)) (*nats.Subscription, error) {
+	return b.conn.Subscribe(b.subject, func(msg *nats.Msg) {
+		var event LinkEvent
+
+		err := json.Unmarshal(msg.Data, &event)
+		if err != nil {
+			slog.Error("Error decoding link event", "err", err)
+			return
+		}
+
+		if event.Instance == b.instance {
+			return
+		}
+
+		fn(event)
+	})
+}
@@ -0,0 +1,93 @@
+package geoip
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IPResolver resolve client ip, proxy header is used only when request come from trusted proxy
+type IPResolver struct {
+	trusted []*net.IPNet
+	header  string
+}
+
+// NewIPResolver return resolver trusting given ips and CIDRs
+func NewIPResolver(header string, proxies ...string) (*IPResolver, error) {
+	r := &IPResolver{header: header}
+
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if strings.Contains(proxy, ":") {
+				proxy += "/128"
+			} else {
+				proxy += "/32"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		r.trusted = append(r.trusted, network)
+	}
+
+	return r, nil
+}
+
+// NewIPResolverFromEnv return resolver trusting proxies listed in TRUSTED_PROXIES separated by comma,
+// client ip is read from CLIENT_IP_HEADER (default X-Forwarded-For)
+func NewIPResolverFromEnv() (*IPResolver, error) {
+	header := os.Getenv("CLIENT_IP_HEADER")
+	if header == "" {
+		header = fiber.HeaderXForwardedFor
+	}
+
+	var proxies []string
+
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return NewIPResolver(header, proxies...)
+}
+
+// Resolve return client ip, addresses in proxy header are checked from the nearest one,
+// first address which is not trusted proxy is client
+func (r *IPResolver) Resolve(c *fiber.Ctx) net.IP {
+	ip := c.Context().RemoteIP()
+	if !r.isTrusted(ip) {
+		return ip
+	}
+
+	values := strings.Split(c.Get(r.header), ",")
+	for i := len(values) - 1; i >= 0; i-- {
+		candidate := net.ParseIP(strings.TrimSpace(values[i]))
+		if candidate == nil {
+			break
+		}
+
+		ip = candidate
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (r *IPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
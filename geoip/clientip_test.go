@@ -0,0 +1,133 @@
+package geoip
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+// resolve return client ip resolved for request with given header, test requests come from 0.0.0.0
+func resolve(t *testing.T, resolver *IPResolver, header string) string {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(resolver.Resolve(c).String())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(fiber.HeaderXForwardedFor, header)
+	}
+
+	resp, err := app.Test(req, -1)
+	testutils.Equal(t, err, nil)
+
+	body, err := io.ReadAll(resp.Body)
+	testutils.Equal(t, err, nil)
+
+	return string(body)
+}
+
+func TestIPResolverResolve(t *testing.T) {
+	cases := []struct {
+		name    string
+		proxies []string
+		header  string
+		want    string
+	}{
+		{name: "no proxies", header: "203.0.113.1", want: "0.0.0.0"},
+		{name: "untrusted remote", proxies: []string{"10.0.0.0/8"}, header: "203.0.113.1", want: "0.0.0.0"},
+		{name: "trusted remote without header", proxies: []string{"0.0.0.0"}, want: "0.0.0.0"},
+		{name: "trusted remote", proxies: []string{"0.0.0.0"}, header: "203.0.113.1", want: "203.0.113.1"},
+		{
+			name:    "chain of trusted proxies",
+			proxies: []string{"0.0.0.0", "10.0.0.0/8"},
+			header:  "203.0.113.1, 10.0.0.2,10.0.0.1",
+			want:    "203.0.113.1",
+		},
+		{
+			name:    "spoofed addresses before client",
+			proxies: []string{"0.0.0.0", "10.0.0.0/8"},
+			header:  "198.51.100.1, 203.0.113.1, 10.0.0.1",
+			want:    "203.0.113.1",
+		},
+		{
+			name:    "untrusted proxy in chain",
+			proxies: []string{"0.0.0.0"},
+			header:  "203.0.113.1, 10.0.0.1",
+			want:    "10.0.0.1",
+		},
+		{name: "every address trusted", proxies: []string{"0.0.0.0", "10.0.0.0/8"}, header: "10.0.0.2, 10.0.0.1", want: "10.0.0.2"},
+		{name: "ipv6 client", proxies: []string{"0.0.0.0"}, header: "2001:db8::1", want: "2001:db8::1"},
+		{
+			name:    "ipv6 chain",
+			proxies: []string{"0.0.0.0", "2001:db8:ffff::/48", "2001:db8:eeee::1"},
+			header:  "2001:db8::1, 2001:db8:eeee::1, 2001:db8:ffff::2",
+			want:    "2001:db8::1",
+		},
+		{
+			name:    "ipv6 proxy is single address",
+			proxies: []string{"0.0.0.0", "2001:db8:eeee::1"},
+			header:  "2001:db8::1, 2001:db8:eeee::2",
+			want:    "2001:db8:eeee::2",
+		},
+		// garbage stop the walk, so address of the nearest trusted hop is used
+		{name: "garbage", proxies: []string{"0.0.0.0"}, header: "unknown", want: "0.0.0.0"},
+		{name: "garbage before client", proxies: []string{"0.0.0.0"}, header: "<script>, 203.0.113.1", want: "203.0.113.1"},
+		{name: "garbage after proxy", proxies: []string{"0.0.0.0", "10.0.0.0/8"}, header: "203.0.113.1, 10.0.0.1, x", want: "0.0.0.0"},
+		{name: "address with port", proxies: []string{"0.0.0.0"}, header: "203.0.113.1:4321", want: "0.0.0.0"},
+		{name: "empty values", proxies: []string{"0.0.0.0"}, header: ",,", want: "0.0.0.0"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver, err := NewIPResolver(fiber.HeaderXForwardedFor, tc.proxies...)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, resolve(t, resolver, tc.header), tc.want)
+		})
+	}
+}
+
+func TestIPResolverResolveIPv6Remote(t *testing.T) {
+	resolver, err := NewIPResolver(fiber.HeaderXForwardedFor, "2001:db8:ffff::/48")
+	testutils.Equal(t, err, nil)
+
+	app := fiber.New()
+
+	resolveFrom := func(remote string) string {
+		fctx := new(fasthttp.RequestCtx)
+		fctx.Init(new(fasthttp.Request), &net.TCPAddr{IP: net.ParseIP(remote), Port: 443}, nil)
+		fctx.Request.Header.Set(fiber.HeaderXForwardedFor, "203.0.113.1")
+
+		c := app.AcquireCtx(fctx)
+		defer app.ReleaseCtx(c)
+
+		return resolver.Resolve(c).String()
+	}
+
+	testutils.Equal(t, resolveFrom("2001:db8:ffff::1"), "203.0.113.1")
+	testutils.Equal(t, resolveFrom("2001:db8::1"), "2001:db8::1")
+}
+
+func TestNewIPResolver(t *testing.T) {
+	_, err := NewIPResolver(fiber.HeaderXForwardedFor, "10.0.0.0/8", "192.168.1.1", "2001:db8::/32", "::1")
+	testutils.Equal(t, err, nil)
+
+	_, err = NewIPResolver(fiber.HeaderXForwardedFor, "proxy")
+	testutils.Equal(t, err == nil, false)
+
+	t.Setenv("TRUSTED_PROXIES", " 0.0.0.0 , ,10.0.0.0/8")
+	t.Setenv("CLIENT_IP_HEADER", "")
+
+	resolver, err := NewIPResolverFromEnv()
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, resolve(t, resolver, "203.0.113.1, 10.0.0.1"), "203.0.113.1")
+}
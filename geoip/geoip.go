@@ -0,0 +1,88 @@
+package geoip
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// LocalCountry key of fiber locals with ISO country code of client
+const LocalCountry = "geoip_country"
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// DB country database in MaxMind format, could be reloaded without restart
+type DB struct {
+	mu     sync.RWMutex
+	path   string
+	reader *maxminddb.Reader
+}
+
+// Open open database file
+func Open(path string) (*DB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{path: path, reader: reader}, nil
+}
+
+// Reload reopen database file, lookups use previous file until new one is opened
+func (db *DB) Reload() error {
+	reader, err := maxminddb.Open(db.path)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	old := db.reader
+	db.reader = reader
+	db.mu.Unlock()
+
+	return old.Close()
+}
+
+// Close close database file
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.reader.Close()
+}
+
+// Country return upper-case ISO 3166-1 alpha-2 code of ip country, empty when unknown
+func (db *DB) Country(ip net.IP) (string, error) {
+	var record countryRecord
+
+	db.mu.RLock()
+	err := db.reader.Lookup(ip, &record)
+	db.mu.RUnlock()
+
+	if err != nil {
+		return "", err
+	}
+
+	if record.Country.ISOCode != "" {
+		return strings.ToUpper(record.Country.ISOCode), nil
+	}
+
+	return strings.ToUpper(record.RegisteredCountry.ISOCode), nil
+}
+
+// GetCountry return country of client stored by middleware
+func GetCountry(c *fiber.Ctx) string {
+	country, _ := c.Locals(LocalCountry).(string)
+
+	return country
+}
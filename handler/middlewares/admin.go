@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Admin allow only requests with `Authorization: Bearer <token>`,
+// all requests are rejected when token is empty
+func Admin(token string) fiber.Handler {
+	expected := []byte("Bearer " + token)
+
+	return func(c *fiber.Ctx) error {
+		if token == "" || subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), expected) != 1 {
+			c.Status(http.StatusUnauthorized)
+			_, err := c.WriteString("Error unauthorized")
+
+			return err
+		}
+
+		return c.Next()
+	}
+}
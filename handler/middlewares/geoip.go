@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/InsideGallery/brf.im/geoip"
+)
+
+// GeoIP store country of client in locals
+func GeoIP(db *geoip.DB, resolver *geoip.IPResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ip := resolver.Resolve(c)
+
+		country, err := db.Country(ip)
+		if err != nil {
+			slog.Error("Error lookup country", "err", err, "ip", ip.String())
+		}
+
+		c.Locals(geoip.LocalCountry, country)
+
+		return c.Next()
+	}
+}
@@ -10,6 +10,19 @@ import (
 	"github.com/InsideGallery/core/server/template"
 )
 
+const (
+	// StatusTemplate name of template for status pages (expired link, etc.)
+	StatusTemplate = "status"
+	// UnlockTemplate name of template with password form of protected link
+	UnlockTemplate = "unlock"
+	// RedirectTemplate name of template with meta refresh or javascript redirect
+	RedirectTemplate = "redirect"
+	// PreviewTemplate name of template with details of short url
+	PreviewTemplate = "preview"
+	// PixelTemplate name of interstitial template which load retargeting pixels before redirect
+	PixelTemplate = "pixel"
+)
+
 // Page describe common page
 type Page struct {
 	Name           string                 `bson:"name"`
@@ -78,6 +91,31 @@ func PageHandler(name string, tmpl *template.Engine) fiber.Handler {
 	}
 }
 
+// RenderStatus render status page with given status code
+func RenderStatus(c *fiber.Ctx, tmpl *template.Engine, status int, pg Page) error {
+	return Render(c, tmpl, StatusTemplate, status, pg)
+}
+
+// Render render named template with given status code
+func Render(c *fiber.Ctx, tmpl *template.Engine, name string, status int, pg Page) error {
+	res, err := tmpl.Execute(name, pg)
+	if err != nil {
+		slog.Error("Error parsing response", "err", err)
+		return err
+	}
+
+	c.Response().Header.Set("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+
+	_, err = c.Write(res)
+	if err != nil {
+		slog.Error("Error sending data", "err", err)
+		return err
+	}
+
+	return nil
+}
+
 func NotFound(tmpl *template.Engine, w http.ResponseWriter) {
 	res, err := tmpl.Execute("404", NewPage("404 | Brief I am", ``, ``, ``, ``, ``, ``))
 	if err != nil {
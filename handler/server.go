@@ -4,7 +4,9 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"os"
 
+	"github.com/InsideGallery/brf.im/geoip"
 	"github.com/InsideGallery/brf.im/handler/middlewares"
 	"github.com/InsideGallery/brf.im/handler/pages"
 	embedded "github.com/InsideGallery/brf.im/resources"
@@ -15,38 +17,56 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 
-	"github.com/InsideGallery/core/db/mongodb"
 	"github.com/InsideGallery/core/server/template"
 )
 
 // Handler describe handler
 type Handler struct {
 	*template.Engine
-	ctx         context.Context
-	app         *fiber.App
-	mongoClient *mongodb.MongoClient
+	ctx    context.Context
+	app    *fiber.App
+	store  shorter.Store
+	geo    *geoip.DB
+	ips    *geoip.IPResolver
+	limits fiber.Storage
 }
 
 // NewHandler return new handler
-func NewHandler(ctx context.Context, app *fiber.App, mongoClient *mongodb.MongoClient) (*Handler, error) {
+func NewHandler(ctx context.Context, app *fiber.App, store shorter.Store) (*Handler, error) {
 	h := &Handler{
-		Engine:      template.NewEngine(),
-		ctx:         ctx,
-		mongoClient: mongoClient,
-		app:         app,
+		Engine: template.NewEngine(),
+		ctx:    ctx,
+		store:  store,
+		app:    app,
+		// without trusted proxies client ip is remote address
+		ips: new(geoip.IPResolver),
 	}
 
 	return h, nil
 }
 
+// WithGeoIP enable country lookup of visitors
+func (h *Handler) WithGeoIP(db *geoip.DB) {
+	h.geo = db
+}
+
+// WithIPResolver set resolver of client ip behind trusted proxies
+func (h *Handler) WithIPResolver(resolver *geoip.IPResolver) {
+	h.ips = resolver
+}
+
+// WithLimiterStorage set storage of rate limits shared by instances, by default limits are per instance
+func (h *Handler) WithLimiterStorage(storage fiber.Storage) {
+	h.limits = storage
+}
+
 func (h *Handler) Run() error {
 	// middleware := webserver.NewMiddleware(
 	//	 middlewares.RecoverFiber,
 	// )
-	st, err := statistic.New()
-	if err != nil {
-		return err
-	}
+	st := statistic.New(h.store)
+	reserved := shorter.NewReservedWords(shorter.GetReservedWordsFromEnv()...)
+	unlocker := shorter.NewUnlocker(shorter.GetUnlockConfigFromEnv())
 
 	h.app.Use(
 		cors.New(),
@@ -56,23 +76,55 @@ func (h *Handler) Run() error {
 				slog.Default().Error("Recovered panic", "err", e)
 			},
 		}),
-		middlewares.New(st),
 	)
 
 	h.app.Get("/", pages.PageHandler("main", h.Engine))
-	h.app.Get("/:shortID", shorter.OpenShortURLHandler())
+	var open []fiber.Handler
+	if h.geo != nil {
+		open = append(open, middlewares.GeoIP(h.geo, h.ips))
+	}
+
+	open = append(open, shorter.OpenShortURLHandler(h.store, st, unlocker, h.Engine))
+	preview := shorter.PreviewShortURLHandler(h.store, h.Engine)
+	// the same limiter is used by both unlock routes, so attempts are counted together
+	unlock := []fiber.Handler{
+		shorter.UnlockLimiter(unlocker, h.ips, h.limits, h.Engine),
+		shorter.UnlockShortURLHandler(h.store, unlocker, h.Engine),
+	}
+
+	h.app.Get("/:shortID", append([]fiber.Handler{shorter.PreviewBySuffix(preview)}, open...)...)
 	h.app.Get("/qr/:shortID", shorter.GetShortURLQRCodeHandler())
-	h.app.Post("/owner", shorter.CreateOwnerHandler())
-	h.app.Delete("/owner/:owner", shorter.RemoveOwnerHandler())
-	h.app.Post("/owner/:owner/url", shorter.CreateShortURLHandler())
-	h.app.Get("/owner/:owner/url", shorter.GetShortURLHandler())
-	h.app.Delete("/owner/:owner/url/:shortID", shorter.RemoveShortURLHandler())
-	h.app.Get("/owner/:owner/url/:shortID", shorter.RemoveShortURLHandler())
+	h.app.Get("/preview/:shortID", preview)
+	h.app.Post("/owner", shorter.CreateOwnerHandler(h.store))
+	h.app.Delete("/owner/:owner", shorter.RemoveOwnerHandler(h.store))
+	h.app.Put("/owner/:owner/generator", shorter.UpdateOwnerGeneratorHandler(h.store))
+	h.app.Post("/owner/:owner/url", shorter.CreateShortURLHandler(h.store, reserved))
+	h.app.Get("/owner/:owner/url", shorter.GetShortURLsHandler(h.store))
+	h.app.Delete("/owner/:owner/url/:shortID", shorter.RemoveShortURLHandler(h.store))
+	h.app.Patch("/owner/:owner/url/:shortID", shorter.PatchShortURLHandler(h.store))
+	h.app.Get("/owner/:owner/url/:shortID/versions", shorter.GetShortURLVersionsHandler(h.store))
+	h.app.Post("/owner/:owner/url/:shortID/versions/:version/rollback", shorter.RollbackShortURLHandler(h.store))
+	h.app.Put("/owner/:owner/url/:shortID/expiration", shorter.UpdateShortURLExpirationHandler(h.store))
+	h.app.Put("/owner/:owner/url/:shortID/schedule", shorter.UpdateShortURLScheduleHandler(h.store))
+	h.app.Put("/owner/:owner/url/:shortID/status", shorter.UpdateShortURLStatusHandler(h.store))
+	h.app.Put("/admin/url/:shortID/status",
+		middlewares.Admin(os.Getenv("ADMIN_TOKEN")),
+		shorter.AdminUpdateShortURLStatusHandler(h.store),
+	)
+	h.app.Put("/owner/:owner/url/:shortID/password", shorter.UpdateShortURLPasswordHandler(h.store))
+	h.app.Get("/owner/:owner/url/:shortID", shorter.GetShortURLHandler(h.store))
+	// registered after static routes, so it does not shadow POST /owner
+	h.app.Post("/:shortID", unlock...)
 	h.app.Use("/s", filesystem.New(filesystem.Config{
 		Root:       http.FS(embedded.GetSource()),
 		PathPrefix: "s",
 		Browse:     true,
 	}))
+	// registered after static routes, so it does not shadow /owner/... and /s/...
+	h.app.Get("/:shortID/*", open...)
+	h.app.Post("/:shortID/*", unlock...)
+
+	reserved.AddRoutes(h.app.GetRoutes(false))
 
 	tmpl, err := template.NewTemplateBySource(embedded.GetTemplate(), "main", "default/index.html")
 	if err != nil {
@@ -81,6 +133,41 @@ func (h *Handler) Run() error {
 
 	h.Add(tmpl)
 
+	tmpl, err = template.NewTemplateBySource(embedded.GetTemplate(), pages.StatusTemplate, "default/status.html")
+	if err != nil {
+		return err
+	}
+
+	h.Add(tmpl)
+
+	tmpl, err = template.NewTemplateBySource(embedded.GetTemplate(), pages.UnlockTemplate, "default/unlock.html")
+	if err != nil {
+		return err
+	}
+
+	h.Add(tmpl)
+
+	tmpl, err = template.NewTemplateBySource(embedded.GetTemplate(), pages.RedirectTemplate, "default/redirect.html")
+	if err != nil {
+		return err
+	}
+
+	h.Add(tmpl)
+
+	tmpl, err = template.NewTemplateBySource(embedded.GetTemplate(), pages.PreviewTemplate, "default/preview.html")
+	if err != nil {
+		return err
+	}
+
+	h.Add(tmpl)
+
+	tmpl, err = template.NewTemplateBySource(embedded.GetTemplate(), pages.PixelTemplate, "default/pixel.html")
+	if err != nil {
+		return err
+	}
+
+	h.Add(tmpl)
+
 	return nil
 }
 
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/brf.im/cache"
+	"github.com/InsideGallery/brf.im/geoip"
+	"github.com/InsideGallery/brf.im/shorter"
+	"github.com/InsideGallery/core/testutils"
+)
+
+func newTestApp(t *testing.T, store shorter.Store, options ...func(h *Handler)) *fiber.App {
+	t.Helper()
+
+	app := fiber.New()
+
+	h, err := NewHandler(context.Background(), app, store)
+	testutils.Equal(t, err, nil)
+
+	for _, option := range options {
+		option(h)
+	}
+
+	testutils.Equal(t, h.Run(), nil)
+
+	return app
+}
+
+func TestReservedRoutes(t *testing.T) {
+	app := newTestApp(t, shorter.NewMemoryStore())
+	owner := primitive.NewObjectID().Hex()
+
+	cases := []struct {
+		name   string
+		body   string
+		status int
+		text   string
+	}{
+		{name: "static mount as alias", body: `{"url":"https://example.com","alias":"s"}`, status: http.StatusBadRequest},
+		{
+			name:   "static mount as prefix",
+			body:   `{"url":"https://example.com","prefix":"s"}`,
+			status: http.StatusBadRequest,
+			text:   "Error prefix is reserved",
+		},
+		{
+			name:   "route as alias",
+			body:   `{"url":"https://example.com","alias":"qr"}`,
+			status: http.StatusBadRequest,
+			text:   "Error alias is reserved",
+		},
+		{
+			name:   "preview route as alias",
+			body:   `{"url":"https://example.com","alias":"preview"}`,
+			status: http.StatusBadRequest,
+			text:   "Error alias is reserved",
+		},
+		{name: "free alias", body: `{"url":"https://example.com","alias":"sx"}`, status: http.StatusCreated},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/owner/"+owner+"/url", strings.NewReader(tc.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			resp, err := app.Test(req, -1)
+			testutils.Equal(t, err, nil)
+
+			body, err := io.ReadAll(resp.Body)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, resp.StatusCode, tc.status)
+
+			if tc.text != "" {
+				testutils.Equal(t, string(body), tc.text)
+			}
+		})
+	}
+}
+
+func TestUnlockLimiter(t *testing.T) {
+	t.Setenv("UNLOCK_SECRET", "secret")
+	t.Setenv("UNLOCK_MAX_ATTEMPTS", "2")
+
+	hash, err := shorter.HashPassword("password")
+	testutils.Equal(t, err, nil)
+
+	store := shorter.NewMemoryStore()
+	model := &shorter.ShortURLModel{
+		ShortID:      "locked",
+		Owner:        primitive.NewObjectID(),
+		URL:          "https://example.com",
+		PasswordHash: hash,
+		// deep links are unlocked by POST /:shortID/*
+		PathPassthrough: true,
+	}
+	testutils.Equal(t, store.InsertShortURL(context.Background(), model), nil)
+
+	// test requests come from 0.0.0.0, which is trusted proxy here
+	resolver, err := geoip.NewIPResolver(fiber.HeaderXForwardedFor, "0.0.0.0")
+	testutils.Equal(t, err, nil)
+
+	app := newTestApp(t, store, func(h *Handler) { h.WithIPResolver(resolver) })
+
+	unlock := func(client string, path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("password=wrong"))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+		req.Header.Set(fiber.HeaderXForwardedFor, client)
+
+		resp, err := app.Test(req, -1)
+		testutils.Equal(t, err, nil)
+
+		return resp.StatusCode
+	}
+
+	testutils.Equal(t, unlock("203.0.113.1", "/locked"), http.StatusUnauthorized)
+	testutils.Equal(t, unlock("203.0.113.1", "/locked"), http.StatusUnauthorized)
+	testutils.Equal(t, unlock("203.0.113.1", "/locked"), http.StatusTooManyRequests)
+	// other client behind the same proxy has own attempts
+	testutils.Equal(t, unlock("203.0.113.2", "/locked"), http.StatusUnauthorized)
+
+	// attempts on link and its deep links are counted together
+	testutils.Equal(t, unlock("203.0.113.3", "/locked/a"), http.StatusUnauthorized)
+	testutils.Equal(t, unlock("203.0.113.3", "/locked"), http.StatusUnauthorized)
+	testutils.Equal(t, unlock("203.0.113.3", "/locked/b"), http.StatusTooManyRequests)
+	testutils.Equal(t, unlock("203.0.113.2", "/locked/a"), http.StatusUnauthorized)
+	testutils.Equal(t, unlock("203.0.113.2", "/locked"), http.StatusTooManyRequests)
+}
+
+func TestUnlockDeepLink(t *testing.T) {
+	t.Setenv("UNLOCK_SECRET", "secret")
+
+	hash, err := shorter.HashPassword("password")
+	testutils.Equal(t, err, nil)
+
+	store := shorter.NewMemoryStore()
+	owner := primitive.NewObjectID()
+
+	links := []*shorter.ShortURLModel{
+		{ShortID: "locked", Owner: owner, URL: "https://example.com", PasswordHash: hash},
+		{ShortID: "deep", Owner: owner, URL: "https://example.com", PasswordHash: hash, PathPassthrough: true},
+	}
+	for _, link := range links {
+		testutils.Equal(t, store.InsertShortURL(context.Background(), link), nil)
+	}
+
+	app := newTestApp(t, store)
+
+	cases := []struct {
+		name     string
+		path     string
+		status   int
+		location string
+	}{
+		{name: "link", path: "/locked", status: http.StatusSeeOther, location: "/locked"},
+		{name: "deep link without passthrough", path: "/locked/a/b", status: http.StatusNotFound},
+		{name: "deep link", path: "/deep/a/b", status: http.StatusSeeOther, location: "/deep/a/b"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader("password=password"))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+
+			resp, err := app.Test(req, -1)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, resp.StatusCode, tc.status)
+			testutils.Equal(t, resp.Header.Get(fiber.HeaderLocation), tc.location)
+			testutils.Equal(t, resp.Header.Get(fiber.HeaderSetCookie) != "", tc.location != "")
+		})
+	}
+}
+
+func TestPreviewClicks(t *testing.T) {
+	store := shorter.NewCachedStore(shorter.NewMemoryStore(), cache.NewLRU[string, shorter.ShortURLModel](100, time.Hour))
+	model := &shorter.ShortURLModel{
+		ShortID:      "clicks",
+		Owner:        primitive.NewObjectID(),
+		URL:          "https://example.com/",
+		RedirectMode: shorter.RedirectFound,
+	}
+	testutils.Equal(t, store.InsertShortURL(context.Background(), model), nil)
+
+	app := newTestApp(t, store)
+
+	get := func(path string) (int, string) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+		testutils.Equal(t, err, nil)
+
+		body, err := io.ReadAll(resp.Body)
+		testutils.Equal(t, err, nil)
+
+		return resp.StatusCode, string(body)
+	}
+
+	clicks := func(path string) string {
+		status, body := get(path)
+		testutils.Equal(t, status, http.StatusOK)
+
+		_, after, found := strings.Cut(body, "Clicks</dt>")
+		testutils.Equal(t, found, true)
+
+		value, _, _ := strings.Cut(strings.TrimSpace(after), "</dd>")
+
+		return strings.TrimPrefix(value, `<dd class="col-sm-8">`)
+	}
+
+	// redirect cache link before clicks
+	status, _ := get("/clicks")
+	testutils.Equal(t, status, http.StatusFound)
+	status, _ = get("/clicks")
+	testutils.Equal(t, status, http.StatusFound)
+
+	// preview show current counter and is not counted as click
+	testutils.Equal(t, clicks("/preview/clicks"), "2")
+	testutils.Equal(t, clicks("/clicks+"), "2")
+
+	current, err := store.ResolveShortURL(context.Background(), model.ShortID)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, current.Clicks, int64(0))
+
+	current, err = shorter.Uncached(store).ResolveShortURL(context.Background(), model.ShortID)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, current.Clicks, int64(2))
+}
+
+func TestRedirectScheme(t *testing.T) {
+	store := shorter.NewMemoryStore()
+	owner := primitive.NewObjectID()
+
+	links := []*shorter.ShortURLModel{
+		{ShortID: "js", Owner: owner, URL: "https://example.com/", RedirectMode: shorter.RedirectJS},
+		// stored before urls were validated
+		{ShortID: "xss", Owner: owner, URL: "javascript:alert(1)", RedirectMode: shorter.RedirectJS},
+		{ShortID: "meta", Owner: owner, URL: "data:text/html,hello", RedirectMode: shorter.RedirectMeta},
+		{
+			ShortID: "pixel",
+			Owner:   owner,
+			URL:     "javascript:alert(1)",
+			Pixels:  []shorter.Pixel{{Type: shorter.PixelFacebook, ID: "123"}},
+		},
+		// script pixels are not supported anymore and are not rendered
+		{
+			ShortID: "script",
+			Owner:   owner,
+			URL:     "https://example.com/",
+			Pixels:  []shorter.Pixel{{Type: "script", URL: "https://example.com/pixel.js"}},
+		},
+	}
+
+	for _, link := range links {
+		testutils.Equal(t, store.InsertShortURL(context.Background(), link), nil)
+	}
+
+	app := newTestApp(t, store)
+
+	cases := []struct {
+		shortID string
+		status  int
+		text    string
+		absent  string
+	}{
+		{shortID: "js", status: http.StatusOK, text: `window.location.replace("https://example.com/")`},
+		{shortID: "xss", status: http.StatusInternalServerError, text: "Error destination is invalid"},
+		{shortID: "meta", status: http.StatusInternalServerError, text: "Error destination is invalid"},
+		{shortID: "pixel", status: http.StatusInternalServerError, text: "Error destination is invalid"},
+		{
+			shortID: "script",
+			status:  http.StatusOK,
+			text:    `window.location.replace("https://example.com/")`,
+			absent:  "pixel.js",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.shortID, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/"+tc.shortID, nil), -1)
+			testutils.Equal(t, err, nil)
+
+			body, err := io.ReadAll(resp.Body)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, resp.StatusCode, tc.status)
+			testutils.Equal(t, strings.Contains(string(body), tc.text), true)
+
+			if tc.absent != "" {
+				testutils.Equal(t, strings.Contains(string(body), tc.absent), false)
+			}
+		})
+	}
+}
@@ -34,9 +34,11 @@ function createShortURL() {
             document.getElementById('qrCodeLink').setAttribute('href', 'data:image/png;base64,'+data.data.qrCode);
             document.getElementById('qrCodeLink').setAttribute('download', data.data.shortID);
             document.getElementById('qrCodeURL').value = data.data.qrCodeURL;
+        } else if (this.readyState == 4 && this.status == 409) {
+            alert("Alias already taken, please choose another one");
         }
     };
-    xhr.send(JSON.stringify({ "url":  document.getElementById('inputFullURL').value, "prefix": document.getElementById('inputPrefix').value }));
+    xhr.send(JSON.stringify({ "url":  document.getElementById('inputFullURL').value, "prefix": document.getElementById('inputPrefix').value, "alias": document.getElementById('inputAlias').value }));
 }
 
 document.addEventListener('DOMContentLoaded', function() {
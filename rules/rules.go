@@ -0,0 +1,283 @@
+package rules
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRules error = errors.New("error invalid rules")
+
+const (
+	ConditionTime     = "time"
+	ConditionWeekday  = "weekday"
+	ConditionLanguage = "language"
+	ConditionReferrer = "referrer"
+	ConditionQuery    = "query"
+	ConditionHeader   = "header"
+
+	HeaderAcceptLanguage = "Accept-Language"
+	HeaderReferer        = "Referer"
+
+	timeLayout = "15:04"
+
+	maxRules       = 20
+	maxConditions  = 10
+	maxValues      = 20
+	maxNameLength  = 64
+	maxValueLength = 256
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Condition describe one check of request:
+//   - time: From <= time of day < To ("15:04"), range could wrap over midnight
+//   - weekday: day is one of Values ("mon", "tue", ...)
+//   - language: Accept-Language contain one of Values, "de" match "de-AT" too
+//   - referrer: referrer host is one of Values or their subdomain
+//   - query, header: parameter or header Name is one of Values, or is not empty when Values are not given
+type Condition struct {
+	Type   string   `bson:"type" json:"type"`
+	Name   string   `bson:"name,omitempty" json:"name,omitempty"`
+	Values []string `bson:"values,omitempty" json:"values,omitempty"`
+	From   string   `bson:"from,omitempty" json:"from,omitempty"`
+	To     string   `bson:"to,omitempty" json:"to,omitempty"`
+}
+
+// Rule send request to url when all its conditions match
+type Rule struct {
+	Conditions []Condition `bson:"conditions" json:"conditions"`
+	URL        string      `bson:"url" json:"url"`
+}
+
+// Request describe request evaluated by rules, time must be in location of link
+type Request struct {
+	Time   time.Time
+	Header func(name string) string
+	Query  func(name string) string
+}
+
+// Evaluate return url of first matching rule
+func Evaluate(rules []Rule, req Request) (string, bool) {
+	for _, rule := range rules {
+		if rule.Match(req) {
+			return rule.URL, true
+		}
+	}
+
+	return "", false
+}
+
+// Match return true if all conditions match request
+func (r Rule) Match(req Request) bool {
+	for _, condition := range r.Conditions {
+		if !condition.Match(req) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Match return true if condition match request
+func (c Condition) Match(req Request) bool {
+	switch c.Type {
+	case ConditionTime:
+		return matchTime(c.From, c.To, req.Time)
+	case ConditionWeekday:
+		return slices.ContainsFunc(c.Values, func(value string) bool {
+			day, ok := weekdays[strings.ToLower(value)]
+			return ok && day == req.Time.Weekday()
+		})
+	case ConditionLanguage:
+		return matchLanguage(c.Values, get(req.Header, HeaderAcceptLanguage))
+	case ConditionReferrer:
+		return matchReferrer(c.Values, get(req.Header, HeaderReferer))
+	case ConditionQuery:
+		return matchValue(c.Values, get(req.Query, c.Name))
+	case ConditionHeader:
+		return matchValue(c.Values, get(req.Header, c.Name))
+	}
+
+	return false
+}
+
+// Validate check rules could be evaluated, urls of rules must be validated by caller
+func Validate(rules []Rule) error {
+	if len(rules) > maxRules {
+		return ErrInvalidRules
+	}
+
+	for _, rule := range rules {
+		if len(rule.Conditions) == 0 || len(rule.Conditions) > maxConditions {
+			return ErrInvalidRules
+		}
+
+		for _, condition := range rule.Conditions {
+			err := condition.Validate()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate check condition has all fields required by its type
+func (c Condition) Validate() error {
+	if len(c.Values) > maxValues || len(c.Name) > maxNameLength {
+		return ErrInvalidRules
+	}
+
+	for _, value := range c.Values {
+		if value == "" || len(value) > maxValueLength {
+			return ErrInvalidRules
+		}
+	}
+
+	switch c.Type {
+	case ConditionTime:
+		if _, ok := parseTime(c.From); !ok {
+			return ErrInvalidRules
+		}
+
+		if _, ok := parseTime(c.To); !ok || c.From == c.To {
+			return ErrInvalidRules
+		}
+	case ConditionWeekday:
+		if len(c.Values) == 0 {
+			return ErrInvalidRules
+		}
+
+		for _, value := range c.Values {
+			if _, ok := weekdays[strings.ToLower(value)]; !ok {
+				return ErrInvalidRules
+			}
+		}
+	case ConditionLanguage, ConditionReferrer:
+		if len(c.Values) == 0 {
+			return ErrInvalidRules
+		}
+	case ConditionQuery, ConditionHeader:
+		if c.Name == "" {
+			return ErrInvalidRules
+		}
+	default:
+		return ErrInvalidRules
+	}
+
+	return nil
+}
+
+func get(fn func(name string) string, name string) string {
+	if fn == nil {
+		return ""
+	}
+
+	return fn(name)
+}
+
+func parseTime(value string) (time.Duration, bool) {
+	t, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
+
+func matchTime(from, to string, now time.Time) bool {
+	start, ok := parseTime(from)
+	if !ok {
+		return false
+	}
+
+	end, ok := parseTime(to)
+	if !ok {
+		return false
+	}
+
+	clock := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+
+	if start < end {
+		return clock >= start && clock < end
+	}
+
+	// range wrap over midnight, e.g. 22:00-06:00
+	return clock >= start || clock < end
+}
+
+func matchLanguage(values []string, header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || isRejected(params) {
+			continue
+		}
+
+		for _, value := range values {
+			if strings.EqualFold(tag, value) ||
+				(len(tag) > len(value) && tag[len(value)] == '-' && strings.EqualFold(tag[:len(value)], value)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isRejected return true if language has zero quality (q=0)
+func isRejected(params string) bool {
+	q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+	if !ok {
+		return false
+	}
+
+	weight, err := strconv.ParseFloat(q, 64)
+
+	return err == nil && weight == 0
+}
+
+func matchReferrer(values []string, referrer string) bool {
+	if referrer == "" {
+		return false
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+
+	for _, value := range values {
+		domain := strings.ToLower(value)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchValue(values []string, value string) bool {
+	if len(values) == 0 {
+		return value != ""
+	}
+
+	return slices.Contains(values, value)
+}
@@ -0,0 +1,262 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+// newRequest return request at given time with given headers and query parameters
+func newRequest(now time.Time, header, query map[string]string) Request {
+	return Request{
+		Time:   now,
+		Header: func(name string) string { return header[name] },
+		Query:  func(name string) string { return query[name] },
+	}
+}
+
+func TestConditionMatch(t *testing.T) {
+	// 2030-01-07 is monday
+	morning := time.Date(2030, 1, 7, 9, 30, 0, 0, time.UTC)
+	night := time.Date(2030, 1, 7, 23, 15, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		condition Condition
+		now       time.Time
+		header    map[string]string
+		query     map[string]string
+		want      bool
+	}{
+		{
+			name:      "time inside range",
+			condition: Condition{Type: ConditionTime, From: "09:00", To: "17:00"},
+			now:       morning,
+			want:      true,
+		},
+		{
+			name:      "time at end of range",
+			condition: Condition{Type: ConditionTime, From: "08:00", To: "09:30"},
+			now:       morning,
+			want:      false,
+		},
+		{
+			name:      "time inside range over midnight",
+			condition: Condition{Type: ConditionTime, From: "22:00", To: "06:00"},
+			now:       night,
+			want:      true,
+		},
+		{
+			name:      "time outside range over midnight",
+			condition: Condition{Type: ConditionTime, From: "22:00", To: "06:00"},
+			now:       morning,
+			want:      false,
+		},
+		{
+			name:      "weekday match",
+			condition: Condition{Type: ConditionWeekday, Values: []string{"sat", "Mon"}},
+			now:       morning,
+			want:      true,
+		},
+		{
+			name:      "weekday mismatch",
+			condition: Condition{Type: ConditionWeekday, Values: []string{"sat", "sun"}},
+			now:       morning,
+			want:      false,
+		},
+		{
+			name:      "language match region",
+			condition: Condition{Type: ConditionLanguage, Values: []string{"de"}},
+			header:    map[string]string{HeaderAcceptLanguage: "en-US;q=0.8, de-AT"},
+			want:      true,
+		},
+		{
+			name:      "language does not match prefix of other language",
+			condition: Condition{Type: ConditionLanguage, Values: []string{"de"}},
+			header:    map[string]string{HeaderAcceptLanguage: "dexx, en"},
+			want:      false,
+		},
+		{
+			name:      "language rejected by zero quality",
+			condition: Condition{Type: ConditionLanguage, Values: []string{"de"}},
+			header:    map[string]string{HeaderAcceptLanguage: "en, de;q=0"},
+			want:      false,
+		},
+		{
+			name:      "referrer subdomain",
+			condition: Condition{Type: ConditionReferrer, Values: []string{"example.com"}},
+			header:    map[string]string{HeaderReferer: "https://news.Example.com/post"},
+			want:      true,
+		},
+		{
+			name:      "referrer with same suffix",
+			condition: Condition{Type: ConditionReferrer, Values: []string{"example.com"}},
+			header:    map[string]string{HeaderReferer: "https://badexample.com/"},
+			want:      false,
+		},
+		{
+			name:      "referrer missing",
+			condition: Condition{Type: ConditionReferrer, Values: []string{"example.com"}},
+			want:      false,
+		},
+		{
+			name:      "query value",
+			condition: Condition{Type: ConditionQuery, Name: "utm_source", Values: []string{"newsletter"}},
+			query:     map[string]string{"utm_source": "newsletter"},
+			want:      true,
+		},
+		{
+			name:      "query other value",
+			condition: Condition{Type: ConditionQuery, Name: "utm_source", Values: []string{"newsletter"}},
+			query:     map[string]string{"utm_source": "ads"},
+			want:      false,
+		},
+		{
+			name:      "query present",
+			condition: Condition{Type: ConditionQuery, Name: "promo"},
+			query:     map[string]string{"promo": "1"},
+			want:      true,
+		},
+		{
+			name:      "query missing",
+			condition: Condition{Type: ConditionQuery, Name: "promo"},
+			want:      false,
+		},
+		{
+			name:      "header value",
+			condition: Condition{Type: ConditionHeader, Name: "X-App", Values: []string{"ios", "android"}},
+			header:    map[string]string{"X-App": "android"},
+			want:      true,
+		},
+		{
+			name:      "header missing",
+			condition: Condition{Type: ConditionHeader, Name: "X-App"},
+			want:      false,
+		},
+		{
+			name:      "unknown type",
+			condition: Condition{Type: "cookie", Name: "session"},
+			want:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.Equal(t, tc.condition.Match(newRequest(tc.now, tc.header, tc.query)), tc.want)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rules := []Rule{
+		{
+			Conditions: []Condition{
+				{Type: ConditionLanguage, Values: []string{"de"}},
+				{Type: ConditionQuery, Name: "promo"},
+			},
+			URL: "https://example.de/promo",
+		},
+		{
+			Conditions: []Condition{{Type: ConditionLanguage, Values: []string{"de"}}},
+			URL:        "https://example.de",
+		},
+		{
+			Conditions: []Condition{{Type: ConditionQuery, Name: "promo"}},
+			URL:        "https://example.com/promo",
+		},
+	}
+
+	cases := []struct {
+		name   string
+		header map[string]string
+		query  map[string]string
+		url    string
+		found  bool
+	}{
+		{
+			name:   "all conditions of first rule match",
+			header: map[string]string{HeaderAcceptLanguage: "de"},
+			query:  map[string]string{"promo": "1"},
+			url:    "https://example.de/promo",
+			found:  true,
+		},
+		{
+			name:   "first matching rule wins",
+			header: map[string]string{HeaderAcceptLanguage: "de"},
+			url:    "https://example.de",
+			found:  true,
+		},
+		{
+			name:  "later rule match",
+			query: map[string]string{"promo": "1"},
+			url:   "https://example.com/promo",
+			found: true,
+		},
+		{
+			name:   "nothing match, default is used",
+			header: map[string]string{HeaderAcceptLanguage: "en"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, found := Evaluate(rules, newRequest(time.Now(), tc.header, tc.query))
+			testutils.Equal(t, found, tc.found)
+			testutils.Equal(t, url, tc.url)
+		})
+	}
+
+	url, found := Evaluate(nil, Request{})
+	testutils.Equal(t, found, false)
+	testutils.Equal(t, url, "")
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules []Rule
+		valid bool
+	}{
+		{name: "no rules", valid: true},
+		{
+			name: "every condition type",
+			rules: []Rule{{URL: "https://example.com", Conditions: []Condition{
+				{Type: ConditionTime, From: "22:00", To: "06:00"},
+				{Type: ConditionWeekday, Values: []string{"sat", "sun"}},
+				{Type: ConditionLanguage, Values: []string{"de"}},
+				{Type: ConditionReferrer, Values: []string{"example.com"}},
+				{Type: ConditionQuery, Name: "promo"},
+				{Type: ConditionHeader, Name: "X-App", Values: []string{"ios"}},
+			}}},
+			valid: true,
+		},
+		{name: "rule without conditions", rules: []Rule{{URL: "https://example.com"}}},
+		{
+			name:  "invalid time",
+			rules: []Rule{{Conditions: []Condition{{Type: ConditionTime, From: "25:00", To: "06:00"}}}},
+		},
+		{
+			name:  "empty time range",
+			rules: []Rule{{Conditions: []Condition{{Type: ConditionTime, From: "06:00", To: "06:00"}}}},
+		},
+		{
+			name:  "unknown weekday",
+			rules: []Rule{{Conditions: []Condition{{Type: ConditionWeekday, Values: []string{"someday"}}}}},
+		},
+		{name: "language without values", rules: []Rule{{Conditions: []Condition{{Type: ConditionLanguage}}}}},
+		{name: "query without name", rules: []Rule{{Conditions: []Condition{{Type: ConditionQuery}}}}},
+		{
+			name:  "empty value",
+			rules: []Rule{{Conditions: []Condition{{Type: ConditionHeader, Name: "X-App", Values: []string{""}}}}},
+		},
+		{name: "unknown type", rules: []Rule{{Conditions: []Condition{{Type: "cookie", Name: "session"}}}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.rules)
+			testutils.Equal(t, err == nil, tc.valid)
+		})
+	}
+}
@@ -1,68 +1,1005 @@
 package shorter
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	t "html/template"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/InsideGallery/brf.im/geoip"
+	"github.com/InsideGallery/brf.im/handler/pages"
+	"github.com/InsideGallery/brf.im/rules"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	qrcode "github.com/skip2/go-qrcode"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
-	"github.com/InsideGallery/core/errors"
+	"github.com/InsideGallery/core/server/template"
 	"github.com/InsideGallery/core/server/webserver"
 )
 
 var (
 	ErrGettingShortID error = errors.New("error getting short id from request")
 	ErrInvalidPrefix  error = errors.New("error invalid prefix")
+	ErrInvalidAlias   error = errors.New("error invalid alias")
 )
 
+// ClickTracker count clicks on short urls and their variants, Track return false when click limit of short url is reached
+type ClickTracker interface {
+	Track(ctx context.Context, shortID, variant string) (bool, error)
+}
+
 const (
 	maxPrefixLength = 11
+	minAliasLength  = 2
+	maxAliasLength  = 64
+
+	scheduleDisplayLayout = "2 Jan 2006 15:04 MST"
 )
 
-var urlLink = GetEnv("URL_LINK")
+var urlLink = GetEnv("URL_LINK")
+
+func GetEnv(name string) string {
+	e := os.Getenv(name)
+	if e == "" {
+		return `https://brf.im`
+	}
+
+	u, err := url.Parse(e)
+	if err != nil {
+		return `https://brf.im`
+	}
+
+	return u.String()
+}
+
+type CreateShortURLRequest struct {
+	ExpirationRequest
+	ScheduleRequest
+	URL       string   `json:"url"`
+	Prefix    string   `json:"prefix"`
+	Alias     string   `json:"alias"`
+	MaxClicks int64    `json:"maxClicks"`
+	Password  string   `json:"password"`
+	Title     string   `json:"title"`
+	Notes     string   `json:"notes"`
+	Tags      []string `json:"tags"`
+	// Rules ordered routing rules, first matching rule pick destination
+	Rules []rules.Rule `json:"rules"`
+	// Platforms destination overrides keyed by platform (ios, android, ...) or device class (mobile, desktop, ...)
+	Platforms map[string]string `json:"platforms"`
+	// Countries destination overrides keyed by ISO 3166-1 alpha-2 country code
+	Countries map[string]string `json:"countries"`
+	// Variants weighted destinations which replace url, Sticky keep chosen variant in cookie
+	Variants []Variant `json:"variants"`
+	Sticky   bool      `json:"sticky"`
+	// QueryPolicy how incoming query is passed to destination: append (default), merge_link, merge_incoming or drop
+	QueryPolicy string `json:"queryPolicy"`
+	// PathPassthrough append rest of path (/<shortID>/rest) to destination
+	PathPassthrough bool `json:"pathPassthrough"`
+	// RedirectMode 301, 302, 307, 308, meta or js, deployment default is used when empty
+	RedirectMode string `json:"redirectMode"`
+	// Pixels retargeting pixels loaded by interstitial page before redirect to third-party destination,
+	// PixelDelay delay of redirect in milliseconds, deployment default is used when empty
+	Pixels     []Pixel `json:"pixels"`
+	PixelDelay int64   `json:"pixelDelay"`
+}
+
+type PasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// ShortURLView short url with its state, as returned to owner
+type ShortURLView struct {
+	ShortURLModel
+	State string `json:"state"`
+}
+
+type CreateOwnerRequest struct {
+	Generator *GeneratorConfig `json:"generator"`
+}
+
+func (req CreateShortURLRequest) GetPrefix(reserved *ReservedWords) (string, error) {
+	if req.Prefix == "" {
+		return "", nil
+	}
+
+	if len(req.Prefix) > maxPrefixLength {
+		return "", ErrInvalidPrefix
+	}
+
+	err := reserved.Check(req.Prefix)
+	if err != nil {
+		return "", errors.Join(ErrInvalidPrefix, err)
+	}
+
+	return req.Prefix, nil
+}
+
+// GetAlias return exact short id requested by user, alias could contain only
+// letters, digits, `-` and `_`, and could not start or end with separator
+func (req CreateShortURLRequest) GetAlias(reserved *ReservedWords) (string, error) {
+	if req.Alias == "" {
+		return "", nil
+	}
+
+	if req.Prefix != "" || len(req.Alias) < minAliasLength || len(req.Alias) > maxAliasLength {
+		return "", ErrInvalidAlias
+	}
+
+	for _, r := range req.Alias {
+		if !isAlphanumeric(r) && r != '-' && r != '_' {
+			return "", ErrInvalidAlias
+		}
+	}
+
+	if strings.ContainsAny(req.Alias[:1]+req.Alias[len(req.Alias)-1:], "-_") {
+		return "", ErrInvalidAlias
+	}
+
+	if reserved.IsReserved(req.Alias) {
+		return "", errors.Join(ErrInvalidAlias, ErrReservedShortID)
+	}
+
+	return req.Alias, nil
+}
+
+func CreateOwnerHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req CreateOwnerRequest
+
+		if len(c.Body()) > 0 {
+			err := json.Unmarshal(c.Body(), &req)
+			if err != nil {
+				slog.Error("Error decoding request", "err", err)
+
+				c.Status(http.StatusBadRequest)
+				_, err := c.WriteString("Error decoding request")
+
+				return err
+			}
+		}
+
+		if req.Generator != nil {
+			err := req.Generator.Validate()
+			if err != nil {
+				slog.Error("Error generator is invalid", "err", err)
+
+				c.Status(http.StatusBadRequest)
+				_, err := c.WriteString("Error generator is invalid")
+
+				return err
+			}
+		}
+
+		ownerID, err := store.CreateOwner(c.Context(), &OwnerModel{
+			Generator: req.Generator,
+		})
+		if err != nil {
+			slog.Error("Error creating owner", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error creating owner")
+
+			return err
+		}
+
+		requestID := c.Get("requestID")
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusCreated)
+
+		resp := webserver.GetSuccessResponse(map[string]string{
+			"owner": ownerID.Hex(),
+		})
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func UpdateOwnerGeneratorHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var cfg GeneratorConfig
+
+		err := json.Unmarshal(c.Body(), &cfg)
+		if err != nil {
+			slog.Error("Error decoding request", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding request")
+
+			return err
+		}
+
+		err = cfg.Validate()
+		if err != nil {
+			slog.Error("Error generator is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error generator is invalid")
+
+			return err
+		}
+
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		err = store.UpdateOwner(c.Context(), &OwnerModel{
+			ID:        id,
+			Generator: &cfg,
+		})
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error owner not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error updating owner", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error updating owner")
+
+			return err
+		}
+
+		requestID := c.Get("requestID")
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusAccepted)
+
+		resp := webserver.GetSuccessResponse(nil)
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func RemoveOwnerHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		err = store.RemoveOwner(c.Context(), id)
+		if err != nil {
+			slog.Error("Error removing owner", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error removing owner")
+
+			return err
+		}
+
+		requestID := c.Get("requestID")
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusAccepted)
+
+		resp := webserver.GetSuccessResponse(nil)
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func CreateShortURLHandler(store Store, reserved *ReservedWords) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req CreateShortURLRequest
+
+		err := json.Unmarshal(c.Body(), &req)
+		if err != nil {
+			slog.Error("Error decoding request", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding request")
+
+			return err
+		}
+
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		err = ValidateURL(req.URL)
+		if err != nil {
+			slog.Error("Error url is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error url is invalid")
+
+			return err
+		}
+
+		prefix, err := req.GetPrefix(reserved)
+		if errors.Is(err, ErrReservedShortID) {
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error prefix is reserved")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error prefix is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error prefix is invalid")
+
+			return err
+		}
+
+		alias, err := req.GetAlias(reserved)
+		if errors.Is(err, ErrReservedShortID) {
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error alias is reserved")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error alias is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error alias is invalid")
+
+			return err
+		}
+
+		expiresAt, err := req.GetExpiresAt(time.Now())
+		if err != nil {
+			slog.Error("Error expiration is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error expiration is invalid")
+
+			return err
+		}
+
+		notBefore, notAfter, timezone, err := req.Schedule()
+		if err != nil {
+			slog.Error("Error schedule is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error schedule is invalid")
+
+			return err
+		}
+
+		if req.MaxClicks < 0 {
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error max clicks is invalid")
+
+			return err
+		}
+
+		var passwordHash string
+
+		if req.Password != "" {
+			passwordHash, err = HashPassword(req.Password)
+			if err != nil {
+				slog.Error("Error password is invalid", "err", err)
+
+				c.Status(http.StatusBadRequest)
+				_, err := c.WriteString("Error password is invalid")
+
+				return err
+			}
+		}
+
+		err = ValidateRules(req.Rules)
+		if err != nil {
+			slog.Error("Error routing rules are invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error routing rules are invalid")
+
+			return err
+		}
+
+		err = ValidatePlatforms(req.Platforms)
+		if err != nil {
+			slog.Error("Error platform overrides are invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error platform overrides are invalid")
+
+			return err
+		}
+
+		err = ValidateQueryPolicy(req.QueryPolicy)
+		if err != nil {
+			slog.Error("Error query policy is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error query policy is invalid")
+
+			return err
+		}
+
+		err = ValidateRedirectMode(req.RedirectMode)
+		if err != nil {
+			slog.Error("Error redirect mode is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error redirect mode is invalid")
+
+			return err
+		}
+
+		err = ValidatePixels(req.Pixels, req.PixelDelay)
+		if err != nil {
+			slog.Error("Error pixels are invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error pixels are invalid")
+
+			return err
+		}
+
+		err = ValidateVariants(req.Variants)
+		if err != nil {
+			slog.Error("Error variants are invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error variants are invalid")
+
+			return err
+		}
+
+		countries, err := NormalizeCountries(req.Countries)
+		if err != nil {
+			slog.Error("Error country overrides are invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error country overrides are invalid")
+
+			return err
+		}
+
+		tags, err := NormalizeTags(req.Tags)
+		if err == nil {
+			err = ValidateMetadata(req.Title, req.Notes)
+		}
+
+		if err != nil {
+			slog.Error("Error metadata is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error metadata is invalid")
+
+			return err
+		}
+
+		now := time.Now().UTC()
+
+		model := &ShortURLModel{
+			Owner:           id,
+			URL:             req.URL,
+			ExpiresAt:       expiresAt,
+			MaxClicks:       req.MaxClicks,
+			NotBefore:       notBefore,
+			NotAfter:        notAfter,
+			Timezone:        timezone,
+			PasswordHash:    passwordHash,
+			Title:           req.Title,
+			Notes:           req.Notes,
+			Tags:            tags,
+			Rules:           req.Rules,
+			Platforms:       req.Platforms,
+			Countries:       countries,
+			Variants:        req.Variants,
+			Sticky:          req.Sticky,
+			QueryPolicy:     req.QueryPolicy,
+			PathPassthrough: req.PathPassthrough,
+			RedirectMode:    req.RedirectMode,
+			Pixels:          req.Pixels,
+			PixelDelay:      req.PixelDelay,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+
+		shortID := alias
+		if alias != "" {
+			err = CreateAliasShortURL(c.Context(), store, alias, model)
+		} else {
+			var generator ShortIDGenerator
+
+			generator, err = GetOwnerGenerator(c.Context(), store, id)
+			if err != nil {
+				slog.Error("Error getting short id generator", "err", err)
+
+				c.Status(http.StatusInternalServerError)
+				_, err := c.WriteString("Error getting short id generator")
+
+				return err
+			}
+
+			shortID, err = CreateShortURL(c.Context(), store, generator, reserved, prefix, model)
+		}
+
+		if errors.Is(err, ErrShortIDExists) {
+			c.Status(http.StatusConflict)
+			_, err := c.WriteString("Error alias already taken")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error creating short url", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error creating short url")
+
+			return err
+		}
+
+		err = store.AddVersion(c.Context(), NewVersion(model, GetActor(c)))
+		if err != nil {
+			slog.Error("Error saving short url version", "err", err, "shortID", shortID)
+		}
+
+		requestID := c.Get("requestID")
+
+		shortURL := strings.Join([]string{urlLink, "/", url.PathEscape(shortID)}, "")
+
+		png, err := qrcode.Encode(shortURL, qrcode.Medium, 256) // nolint:mnd
+		if err != nil {
+			slog.Error("Error creating qr code", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error creating qr code")
+
+			return err
+		}
+
+		sEnc := base64.StdEncoding.EncodeToString(png)
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusCreated)
+
+		resp := webserver.GetSuccessResponse(map[string]any{
+			"qrCode":    sEnc,
+			"shortID":   url.PathEscape(shortID),
+			"shortURL":  shortURL,
+			"qrCodeURL": strings.Join([]string{urlLink, "/qr/", url.PathEscape(shortID)}, ""),
+		})
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func PatchShortURLHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req PatchShortURLRequest
+
+		err := json.Unmarshal(c.Body(), &req)
+		if err != nil {
+			slog.Error("Error decoding request", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding request")
+
+			return err
+		}
+
+		shortID := c.Params("shortID")
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		shortURL, err := store.GetShortURL(c.Context(), shortID, id)
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error getting short url", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error getting short url")
+
+			return err
+		}
+
+		err = req.Apply(shortURL)
+		if err != nil {
+			slog.Error("Error update is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error update is invalid")
+
+			return err
+		}
+
+		err = SaveShortURL(c.Context(), store, shortURL, GetActor(c))
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error updating short url", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error updating short url")
+
+			return err
+		}
+
+		shortURL.ShortID = url.PathEscape(shortURL.ShortID)
+
+		requestID := c.Get("requestID")
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusOK)
+
+		resp := webserver.GetSuccessResponse(ShortURLView{
+			ShortURLModel: *shortURL,
+			State:         shortURL.State(time.Now()),
+		})
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func GetShortURLVersionsHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		shortID := c.Params("shortID")
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		var versions []VersionModel
+
+		_, err = store.GetShortURL(c.Context(), shortID, id)
+		if err == nil {
+			versions, err = store.GetVersions(c.Context(), shortID)
+		}
+
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error getting short url versions", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error getting short url versions")
+
+			return err
+		}
+
+		for i := range versions {
+			versions[i].ShortID = url.PathEscape(versions[i].ShortID)
+			versions[i].Link.ShortID = versions[i].ShortID
+		}
+
+		requestID := c.Get("requestID")
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusOK)
+
+		resp := webserver.GetSuccessResponse(map[string]any{
+			"versions": versions,
+		})
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func RollbackShortURLHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		shortID := c.Params("shortID")
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		versionID, err := primitive.ObjectIDFromHex(c.Params("version"))
+		if err != nil {
+			slog.Error("Error decoding version", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding version")
+
+			return err
+		}
+
+		var version *VersionModel
+
+		shortURL, err := store.GetShortURL(c.Context(), shortID, id)
+		if err == nil {
+			version, err = store.GetVersion(c.Context(), shortID, versionID)
+		}
+
+		if err == nil && version.Owner != id {
+			err = ErrNotFound
+		}
+
+		if err == nil {
+			shortURL = Rollback(shortURL, version)
+			err = SaveShortURL(c.Context(), store, shortURL, GetActor(c))
+		}
+
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url version not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error rolling back short url", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error rolling back short url")
+
+			return err
+		}
+
+		shortURL.ShortID = url.PathEscape(shortURL.ShortID)
+
+		requestID := c.Get("requestID")
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusOK)
+
+		resp := webserver.GetSuccessResponse(ShortURLView{
+			ShortURLModel: *shortURL,
+			State:         shortURL.State(time.Now()),
+		})
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func UpdateShortURLExpirationHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req ExpirationRequest
+
+		err := json.Unmarshal(c.Body(), &req)
+		if err != nil {
+			slog.Error("Error decoding request", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding request")
+
+			return err
+		}
+
+		expiresAt, err := req.GetExpiresAt(time.Now())
+		if err != nil {
+			slog.Error("Error expiration is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error expiration is invalid")
+
+			return err
+		}
+
+		shortID := c.Params("shortID")
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		shortURL, err := store.GetShortURL(c.Context(), shortID, id)
+		if err == nil {
+			shortURL.ExpiresAt = expiresAt
+			err = SaveShortURL(c.Context(), store, shortURL, GetActor(c))
+		}
+
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error updating short url", "err", err)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error updating short url")
+
+			return err
+		}
+
+		requestID := c.Get("requestID")
+
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusAccepted)
+
+		resp := webserver.GetSuccessResponse(nil)
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func UpdateShortURLScheduleHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req ScheduleRequest
+
+		err := json.Unmarshal(c.Body(), &req)
+		if err != nil {
+			slog.Error("Error decoding request", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding request")
+
+			return err
+		}
+
+		notBefore, notAfter, timezone, err := req.Schedule()
+		if err != nil {
+			slog.Error("Error schedule is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error schedule is invalid")
+
+			return err
+		}
 
-func GetEnv(name string) string {
-	e := os.Getenv(name)
-	if e == "" {
-		return `https://brf.im`
-	}
+		shortID := c.Params("shortID")
+		owner := c.Params("owner")
 
-	u, err := url.Parse(e)
-	if err != nil {
-		return `https://brf.im`
-	}
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
 
-	return u.String()
-}
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
 
-type CreateShortURLRequest struct {
-	URL    string `json:"url"`
-	Prefix string `json:"prefix"`
-}
+			return err
+		}
 
-func (req CreateShortURLRequest) GetPrefix() (string, error) {
-	if strings.Contains(req.Prefix, "/") || len(req.Prefix) > maxPrefixLength {
-		return "", ErrInvalidPrefix
-	}
+		shortURL, err := store.GetShortURL(c.Context(), shortID, id)
+		if err == nil {
+			shortURL.NotBefore = notBefore
+			shortURL.NotAfter = notAfter
+			shortURL.Timezone = timezone
+			err = SaveShortURL(c.Context(), store, shortURL, GetActor(c))
+		}
 
-	return req.Prefix, nil
-}
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
 
-func CreateOwnerHandler() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		ownerID, err := CreateOwner(c.Context())
 		if err != nil {
-			slog.Error("Error creating owner", "err", err)
+			slog.Error("Error updating short url", "err", err)
 
 			c.Status(http.StatusInternalServerError)
-			_, err := c.WriteString("Error creating owner")
+			_, err := c.WriteString("Error updating short url")
 
 			return err
 		}
@@ -70,10 +1007,10 @@ func CreateOwnerHandler() fiber.Handler {
 		requestID := c.Get("requestID")
 
 		c.Response().Header.Set("requestID", requestID)
-		c.Status(http.StatusCreated)
+		c.Status(http.StatusAccepted)
 
-		resp := webserver.GetSuccessResponse(map[string]string{
-			"owner": ownerID.Hex(),
+		resp := webserver.GetSuccessResponse(map[string]any{
+			"state": shortURL.State(time.Now()),
 		})
 
 		data, err := json.Marshal(resp)
@@ -87,8 +1024,31 @@ func CreateOwnerHandler() fiber.Handler {
 	}
 }
 
-func RemoveOwnerHandler() fiber.Handler {
+func UpdateShortURLStatusHandler(store Store) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		var req StatusRequest
+
+		err := json.Unmarshal(c.Body(), &req)
+		if err != nil {
+			slog.Error("Error decoding request", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding request")
+
+			return err
+		}
+
+		err = req.Validate(false)
+		if err != nil {
+			slog.Error("Error status is invalid", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error status is invalid")
+
+			return err
+		}
+
+		shortID := c.Params("shortID")
 		owner := c.Params("owner")
 
 		id, err := primitive.ObjectIDFromHex(owner)
@@ -101,12 +1061,30 @@ func RemoveOwnerHandler() fiber.Handler {
 			return err
 		}
 
-		err = RemoveOwner(c.Context(), id)
+		shortURL := &ShortURLModel{ShortID: shortID, Owner: id}
+		req.Apply(shortURL)
+
+		_, err = SaveShortURLStatus(c.Context(), store, shortURL, false, GetActor(c))
+
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if errors.Is(err, ErrBlocked) {
+			c.Status(http.StatusConflict)
+			_, err := c.WriteString("Error short url is blocked")
+
+			return err
+		}
+
 		if err != nil {
-			slog.Error("Error removing owner", "err", err)
+			slog.Error("Error updating short url", "err", err)
 
 			c.Status(http.StatusInternalServerError)
-			_, err := c.WriteString("Error removing owner")
+			_, err := c.WriteString("Error updating short url")
 
 			return err
 		}
@@ -129,9 +1107,10 @@ func RemoveOwnerHandler() fiber.Handler {
 	}
 }
 
-func CreateShortURLHandler() fiber.Handler {
+// AdminUpdateShortURLStatusHandler change status of any short url, including block and unblock
+func AdminUpdateShortURLStatusHandler(store Store) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var req CreateShortURLRequest
+		var req StatusRequest
 
 		err := json.Unmarshal(c.Body(), &req)
 		if err != nil {
@@ -143,63 +1122,128 @@ func CreateShortURLHandler() fiber.Handler {
 			return err
 		}
 
-		owner := c.Params("owner")
-
-		id, err := primitive.ObjectIDFromHex(owner)
+		err = req.Validate(true)
 		if err != nil {
-			slog.Error("Error decoding owner", "err", err)
+			slog.Error("Error status is invalid", "err", err)
 
 			c.Status(http.StatusBadRequest)
-			_, err := c.WriteString("Error decoding owner")
+			_, err := c.WriteString("Error status is invalid")
 
 			return err
 		}
 
-		prefix, err := req.GetPrefix()
-		if err != nil {
-			slog.Error("Error prefix is invalid", "err", err)
+		shortID := c.Params("shortID")
 
-			c.Status(http.StatusBadRequest)
-			_, err := c.WriteString("Error prefix is invalid")
+		shortURL := &ShortURLModel{ShortID: shortID}
+		req.Apply(shortURL)
+
+		shortURL, err = SaveShortURLStatus(c.Context(), store, shortURL, true, ActorAdmin)
+
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
 
 			return err
 		}
 
-		shortID, err := CreateShortURL(c.Context(), prefix, req.URL, id)
 		if err != nil {
-			slog.Error("Error creating short url", "err", err)
+			slog.Error("Error updating short url", "err", err)
 
 			c.Status(http.StatusInternalServerError)
-			_, err := c.WriteString("Error creating short url")
+			_, err := c.WriteString("Error updating short url")
 
 			return err
 		}
 
+		slog.Info("Short url status changed by admin", "shortID", shortID, "status", shortURL.GetStatus())
+
 		requestID := c.Get("requestID")
 
-		shortURL := strings.Join([]string{urlLink, "/", url.PathEscape(shortID)}, "")
+		c.Response().Header.Set("requestID", requestID)
+		c.Status(http.StatusAccepted)
 
-		png, err := qrcode.Encode(shortURL, qrcode.Medium, 256) // nolint:mnd
+		resp := webserver.GetSuccessResponse(nil)
+
+		data, err := json.Marshal(resp)
 		if err != nil {
-			slog.Error("Error creating qr code", "err", err)
+			return err
+		}
+
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func UpdateShortURLPasswordHandler(store Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req PasswordRequest
+
+		err := json.Unmarshal(c.Body(), &req)
+		if err != nil {
+			slog.Error("Error decoding request", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding request")
+
+			return err
+		}
+
+		var passwordHash string
+
+		if req.Password != "" {
+			passwordHash, err = HashPassword(req.Password)
+			if err != nil {
+				slog.Error("Error password is invalid", "err", err)
+
+				c.Status(http.StatusBadRequest)
+				_, err := c.WriteString("Error password is invalid")
+
+				return err
+			}
+		}
+
+		shortID := c.Params("shortID")
+		owner := c.Params("owner")
+
+		id, err := primitive.ObjectIDFromHex(owner)
+		if err != nil {
+			slog.Error("Error decoding owner", "err", err)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error decoding owner")
+
+			return err
+		}
+
+		shortURL, err := store.GetShortURL(c.Context(), shortID, id)
+		if err == nil {
+			shortURL.PasswordHash = passwordHash
+			err = SaveShortURL(c.Context(), store, shortURL, GetActor(c))
+		}
+
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error updating short url", "err", err)
 
 			c.Status(http.StatusInternalServerError)
-			_, err := c.WriteString("Error creating qr code")
+			_, err := c.WriteString("Error updating short url")
 
 			return err
 		}
 
-		sEnc := base64.StdEncoding.EncodeToString(png)
+		requestID := c.Get("requestID")
 
 		c.Response().Header.Set("requestID", requestID)
-		c.Status(http.StatusCreated)
+		c.Status(http.StatusAccepted)
 
-		resp := webserver.GetSuccessResponse(map[string]any{
-			"qrCode":    sEnc,
-			"shortID":   url.PathEscape(shortID),
-			"shortURL":  shortURL,
-			"qrCodeURL": strings.Join([]string{urlLink, "/qr/", url.PathEscape(shortID)}, ""),
-		})
+		resp := webserver.GetSuccessResponse(nil)
 
 		data, err := json.Marshal(resp)
 		if err != nil {
@@ -212,7 +1256,7 @@ func CreateShortURLHandler() fiber.Handler {
 	}
 }
 
-func RemoveShortURLHandler() fiber.Handler {
+func RemoveShortURLHandler(store Store) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		shortID := c.Params("shortID")
 		owner := c.Params("owner")
@@ -227,7 +1271,7 @@ func RemoveShortURLHandler() fiber.Handler {
 			return err
 		}
 
-		err = RemoveShortURL(c.Context(), shortID, id)
+		err = store.RemoveShortURL(c.Context(), shortID, id)
 		if err != nil {
 			slog.Error("Error removing short url", "err", err)
 
@@ -255,7 +1299,7 @@ func RemoveShortURLHandler() fiber.Handler {
 	}
 }
 
-func GetShortURLsHandler() fiber.Handler {
+func GetShortURLsHandler(store Store) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		owner := c.Params("owner")
 
@@ -269,7 +1313,9 @@ func GetShortURLsHandler() fiber.Handler {
 			return err
 		}
 
-		urls, err := GetShortURLs(c.Context(), id)
+		urls, err := store.GetShortURLs(c.Context(), id, ShortURLFilter{
+			Tag: strings.ToLower(c.Query("tag")),
+		})
 		if err != nil {
 			slog.Error("Error getting short urls", "err", err)
 
@@ -279,13 +1325,32 @@ func GetShortURLsHandler() fiber.Handler {
 			return err
 		}
 
+		now := time.Now()
+		state := c.Query("state")
+		views := make([]ShortURLView, 0, len(urls))
+
+		for i := range urls {
+			view := ShortURLView{ShortURLModel: urls[i], State: urls[i].State(now)}
+			if state != "" && view.State != state {
+				continue
+			}
+
+			view.ShortID = url.PathEscape(view.ShortID)
+			views = append(views, view)
+		}
+
+		// newest links first
+		slices.SortFunc(views, func(a, b ShortURLView) int {
+			return b.CreatedAt.Compare(a.CreatedAt)
+		})
+
 		requestID := c.Get("requestID")
 
 		c.Response().Header.Set("requestID", requestID)
 		c.Status(http.StatusOK)
 
 		resp := webserver.GetSuccessResponse(map[string]any{
-			"urls": urls,
+			"urls": views,
 		})
 
 		data, err := json.Marshal(resp)
@@ -299,7 +1364,7 @@ func GetShortURLsHandler() fiber.Handler {
 	}
 }
 
-func GetShortURLHandler() fiber.Handler {
+func GetShortURLHandler(store Store) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		shortID := c.Params("shortID")
 		owner := c.Params("owner")
@@ -314,7 +1379,14 @@ func GetShortURLHandler() fiber.Handler {
 			return err
 		}
 
-		shortURL, err := GetShortURL(c.Context(), shortID, id)
+		shortURL, err := store.GetShortURL(c.Context(), shortID, id)
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
 		if err != nil {
 			slog.Error("Error getting short url", "err", err)
 
@@ -329,10 +1401,11 @@ func GetShortURLHandler() fiber.Handler {
 		c.Response().Header.Set("requestID", requestID)
 		c.Status(http.StatusOK)
 
-		resp := webserver.GetSuccessResponse(map[string]any{
-			"url":     shortURL.URL,
-			"shortID": shortURL.ShortID,
-			"owner":   shortURL.Owner.Hex(),
+		shortURL.ShortID = url.PathEscape(shortURL.ShortID)
+
+		resp := webserver.GetSuccessResponse(ShortURLView{
+			ShortURLModel: *shortURL,
+			State:         shortURL.State(time.Now()),
 		})
 
 		data, err := json.Marshal(resp)
@@ -346,11 +1419,18 @@ func GetShortURLHandler() fiber.Handler {
 	}
 }
 
-func OpenShortURLHandler() fiber.Handler {
+func OpenShortURLHandler(store Store, tracker ClickTracker, unlocker *Unlocker, tmpl *template.Engine) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		shortID := c.Params("shortID")
 
-		u, err := GetFullURL(c.Context(), shortID)
+		shortURL, err := store.ResolveShortURL(c.Context(), shortID)
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
 		if err != nil {
 			slog.Error("Error getting short url", "err", err, "shortID", shortID)
 
@@ -360,7 +1440,53 @@ func OpenShortURLHandler() fiber.Handler {
 			return err
 		}
 
-		rawURL, err := url.Parse(u)
+		rest := GetRestPath(c)
+		if rest != "" && !shortURL.PathPassthrough {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		now := time.Now()
+
+		if shortURL.IsExpired(now) {
+			return renderExpired(c, tmpl)
+		}
+
+		switch shortURL.GetStatus() {
+		case StatusBlocked:
+			return renderBlocked(c, tmpl, shortURL)
+		case StatusPaused:
+			return renderPaused(c, tmpl, shortURL)
+		}
+
+		switch shortURL.State(now) {
+		case LinkStateScheduled:
+			return renderScheduled(c, tmpl, shortURL, now)
+		case LinkStateEnded:
+			return renderEnded(c, tmpl)
+		}
+
+		if shortURL.IsProtected() && !unlocker.Verify(shortURL, c.Cookies(UnlockCookie), time.Now()) {
+			return renderUnlock(c, tmpl, http.StatusUnauthorized, "")
+		}
+
+		if len(shortURL.Platforms) > 0 {
+			c.Vary(fiber.HeaderUserAgent)
+		}
+
+		if len(shortURL.Rules) > 0 || len(shortURL.Countries) > 0 || len(shortURL.Variants) > 0 {
+			// rules, country and variant could not be expressed by Vary header, so response must not be cached
+			c.Set(fiber.HeaderCacheControl, "private, no-store")
+		}
+
+		visitor := NewVisitor(c)
+		visitor.Variant = ChooseVariant(c, shortURL)
+
+		destination, variant := shortURL.Destination(visitor)
+
+		rawURL, err := url.Parse(destination)
 		if err != nil {
 			slog.Error("Error parse url", "err", err, "shortID", shortID)
 
@@ -370,14 +1496,189 @@ func OpenShortURLHandler() fiber.Handler {
 			return err
 		}
 
-		if rawURL.RawQuery != "" {
-			rawURL.RawQuery = rawURL.RawQuery + "&" + c.Context().QueryArgs().String()
-		} else {
-			rawURL.RawQuery = c.Context().QueryArgs().String()
+		err = JoinPath(rawURL, rest)
+		if err != nil {
+			slog.Error("Error path is invalid", "err", err, "shortID", shortID)
+
+			c.Status(http.StatusBadRequest)
+			_, err := c.WriteString("Error path is invalid")
+
+			return err
+		}
+
+		allowed, err := tracker.Track(c.Context(), shortID, variant)
+		if err != nil {
+			slog.Error("Error track redirect", "err", err, "shortID", shortID)
+
+			// limited links could not be opened without counting the click
+			if shortURL.MaxClicks > 0 {
+				c.Status(http.StatusInternalServerError)
+				_, err := c.WriteString("Error track redirect")
+
+				return err
+			}
+		} else if !allowed {
+			return pages.RenderStatus(c, tmpl, http.StatusGone, pages.NewPage(
+				"Link exhausted | Brief I am", ``, ``, ``, ``,
+				`Link exhausted`,
+				`This short link has reached its usage limit and is no longer available.`,
+			))
+		}
+
+		rawURL.RawQuery = MergeQuery(rawURL.RawQuery, string(c.Request().URI().QueryString()), shortURL.QueryPolicy)
+
+		if ShouldRenderPixels(c, shortURL, rawURL) {
+			return renderPixels(c, tmpl, shortURL, rawURL.String())
+		}
+
+		return Redirect(c, tmpl, shortURL.GetRedirectMode(), rawURL.String())
+	}
+}
+
+// UnlockShortURLHandler check password of protected link and issue unlock cookie
+func UnlockShortURLHandler(store Store, unlocker *Unlocker, tmpl *template.Engine) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		shortID := c.Params("shortID")
+
+		shortURL, err := store.ResolveShortURL(c.Context(), shortID)
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error getting short url", "err", err, "shortID", shortID)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error getting short url")
+
+			return err
+		}
+
+		// deep link of link without passthrough does not exist, so it could not be unlocked too
+		if GetRestPath(c) != "" && !shortURL.PathPassthrough {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if shortURL.IsExpired(time.Now()) {
+			return renderExpired(c, tmpl)
+		}
+
+		if !shortURL.IsProtected() {
+			return c.Redirect(c.OriginalURL(), http.StatusSeeOther)
+		}
+
+		if !shortURL.CheckPassword(c.FormValue("password")) {
+			return renderUnlock(c, tmpl, http.StatusUnauthorized, "Wrong password, try again.")
 		}
 
-		return c.Redirect(rawURL.String(), http.StatusPermanentRedirect)
+		value, expiresAt := unlocker.Sign(shortURL, time.Now())
+
+		c.Cookie(&fiber.Cookie{
+			Name:     UnlockCookie,
+			Value:    value,
+			Path:     "/" + shortID,
+			Expires:  expiresAt,
+			Secure:   strings.HasPrefix(urlLink, "https://"),
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteLaxMode,
+		})
+
+		return c.Redirect(c.OriginalURL(), http.StatusSeeOther)
+	}
+}
+
+// UnlockLimiter limit wrong password attempts per client and short url, client ip is resolved behind
+// trusted proxies, storage could be nil, then attempts are counted per instance
+func UnlockLimiter(
+	unlocker *Unlocker,
+	resolver *geoip.IPResolver,
+	storage fiber.Storage,
+	tmpl *template.Engine,
+) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        unlocker.maxAttempts,
+		Expiration: unlocker.window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "unlock|" + resolver.Resolve(c).String() + "|" + c.Params("shortID")
+		},
+		Storage: storage,
+		LimitReached: func(c *fiber.Ctx) error {
+			return renderUnlock(c, tmpl, http.StatusTooManyRequests, "Too many wrong attempts, try again later.")
+		},
+		SkipSuccessfulRequests: true,
+	})
+}
+
+func renderExpired(c *fiber.Ctx, tmpl *template.Engine) error {
+	return pages.RenderStatus(c, tmpl, http.StatusGone, pages.NewPage(
+		"Link expired | Brief I am", ``, ``, ``, ``,
+		`Link expired`,
+		`This short link has expired and is no longer available.`,
+	))
+}
+
+func renderScheduled(c *fiber.Ctx, tmpl *template.Engine, shortURL *ShortURLModel, now time.Time) error {
+	notBefore := shortURL.NotBefore.In(shortURL.Location())
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(notBefore.Sub(now).Seconds())+1))
+
+	return pages.RenderStatus(c, tmpl, http.StatusServiceUnavailable, pages.NewPage(
+		"Coming soon | Brief I am", ``, ``, ``, ``,
+		`Coming soon`,
+		t.HTML("This short link will be available from "+t.HTMLEscapeString(notBefore.Format(scheduleDisplayLayout))+"."), // nolint:gosec
+	))
+}
+
+func renderPaused(c *fiber.Ctx, tmpl *template.Engine, shortURL *ShortURLModel) error {
+	text := `This short link is temporarily unavailable, please try again later.`
+	if shortURL.StatusMessage != "" {
+		text = shortURL.StatusMessage
+	}
+
+	return pages.RenderStatus(c, tmpl, http.StatusServiceUnavailable, pages.NewPage(
+		"Temporarily unavailable | Brief I am", ``, ``, ``, ``,
+		`Temporarily unavailable`,
+		t.HTML(t.HTMLEscapeString(text)), // nolint:gosec
+	))
+}
+
+func renderBlocked(c *fiber.Ctx, tmpl *template.Engine, shortURL *ShortURLModel) error {
+	text := `This short link has been blocked.`
+	if shortURL.StatusMessage != "" {
+		text = shortURL.StatusMessage
 	}
+
+	return pages.RenderStatus(c, tmpl, http.StatusForbidden, pages.NewPage(
+		"Link blocked | Brief I am", ``, ``, ``, ``,
+		`Link blocked`,
+		t.HTML(t.HTMLEscapeString(text)), // nolint:gosec
+	))
+}
+
+func renderEnded(c *fiber.Ctx, tmpl *template.Engine) error {
+	return pages.RenderStatus(c, tmpl, http.StatusGone, pages.NewPage(
+		"Link ended | Brief I am", ``, ``, ``, ``,
+		`Link ended`,
+		`This short link is no longer active.`,
+	))
+}
+
+func renderUnlock(c *fiber.Ctx, tmpl *template.Engine, status int, message string) error {
+	pg := pages.NewPage(
+		"Protected link | Brief I am", ``, ``, ``, ``,
+		`Protected link`,
+		`This short link is protected with password.`,
+	)
+	pg.Add("action", c.OriginalURL())
+	pg.Add("error", message)
+
+	return pages.Render(c, tmpl, pages.UnlockTemplate, status, pg)
 }
 
 func GetShortURLQRCodeHandler() fiber.Handler {
@@ -0,0 +1,544 @@
+package shorter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const bucketShortURLsByOwner = "short_urls_by_owner"
+
+var ownerKeySeparator = []byte{0}
+
+// BoltStore embedded single-file store based on bbolt
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore open (or create) database file and return new bolt store
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, nil) // nolint:mnd
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{
+			CollectionOwner, CollectionShortURLs, CollectionCounters, CollectionVersions, bucketShortURLsByOwner,
+		} {
+			_, err := tx.CreateBucketIfNotExists([]byte(name))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Close close database file
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) NextSequence(_ context.Context, name string) (uint64, error) {
+	var seq uint64
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		counters := tx.Bucket([]byte(CollectionCounters))
+
+		if data := counters.Get([]byte(name)); data != nil {
+			seq = binary.BigEndian.Uint64(data)
+		}
+
+		seq++
+
+		return counters.Put([]byte(name), binary.BigEndian.AppendUint64(nil, seq))
+	})
+
+	return seq, err
+}
+
+func (s *BoltStore) CreateOwner(_ context.Context, model *OwnerModel) (primitive.ObjectID, error) {
+	model.ID = primitive.NewObjectID()
+
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(CollectionOwner)).Put(model.ID[:], data)
+	})
+
+	return model.ID, err
+}
+
+func (s *BoltStore) GetOwner(_ context.Context, id primitive.ObjectID) (*OwnerModel, error) {
+	model := new(OwnerModel)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(CollectionOwner)).Get(id[:])
+		if data == nil {
+			return ErrNotFound
+		}
+
+		return bson.Unmarshal(data, model)
+	})
+
+	return model, err
+}
+
+func (s *BoltStore) UpdateOwner(_ context.Context, model *OwnerModel) error {
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		owners := tx.Bucket([]byte(CollectionOwner))
+		if owners.Get(model.ID[:]) == nil {
+			return ErrNotFound
+		}
+
+		return owners.Put(model.ID[:], data)
+	})
+}
+
+func (s *BoltStore) RemoveOwner(_ context.Context, id primitive.ObjectID) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+		index := tx.Bucket([]byte(bucketShortURLsByOwner))
+		prefix := ownerKey(id, "")
+
+		var keys [][]byte
+
+		c := index.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, k)
+		}
+
+		for _, k := range keys {
+			err := urls.Delete(k[len(prefix):])
+			if err != nil {
+				return err
+			}
+
+			err = index.Delete(k)
+			if err != nil {
+				return err
+			}
+
+			err = deleteBoltVersions(tx, string(k[len(prefix):]))
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Bucket([]byte(CollectionOwner)).Delete(id[:])
+	})
+}
+
+func (s *BoltStore) InsertShortURL(_ context.Context, model *ShortURLModel) error {
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+		if urls.Get([]byte(model.ShortID)) != nil {
+			return ErrShortIDExists
+		}
+
+		err := urls.Put([]byte(model.ShortID), data)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket([]byte(bucketShortURLsByOwner)).Put(ownerKey(model.Owner, model.ShortID), nil)
+	})
+}
+
+func (s *BoltStore) RemoveShortURL(_ context.Context, shortID string, owner primitive.ObjectID) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+
+		model, err := getBoltShortURL(urls, shortID)
+		if err != nil {
+			return err
+		}
+
+		if model == nil || model.Owner != owner {
+			return nil
+		}
+
+		err = urls.Delete([]byte(shortID))
+		if err != nil {
+			return err
+		}
+
+		err = deleteBoltVersions(tx, shortID)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket([]byte(bucketShortURLsByOwner)).Delete(ownerKey(owner, shortID))
+	})
+}
+
+func (s *BoltStore) GetShortURLs(
+	_ context.Context,
+	owner primitive.ObjectID,
+	filter ShortURLFilter,
+) ([]ShortURLModel, error) {
+	var result []ShortURLModel
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+		prefix := ownerKey(owner, "")
+
+		c := tx.Bucket([]byte(bucketShortURLsByOwner)).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			model, err := getBoltShortURL(urls, string(k[len(prefix):]))
+			if err != nil {
+				return err
+			}
+
+			if model != nil && filter.Match(model) {
+				result = append(result, *model)
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *BoltStore) GetShortURL(
+	_ context.Context,
+	shortID string,
+	owner primitive.ObjectID,
+) (*ShortURLModel, error) {
+	model, err := s.get(shortID)
+	if err != nil {
+		return new(ShortURLModel), err
+	}
+
+	if model.Owner != owner {
+		return new(ShortURLModel), ErrNotFound
+	}
+
+	return model, nil
+}
+
+func (s *BoltStore) ResolveShortURL(_ context.Context, shortID string) (*ShortURLModel, error) {
+	model, err := s.get(shortID)
+	if err != nil {
+		return new(ShortURLModel), err
+	}
+
+	return model, nil
+}
+
+func (s *BoltStore) UpdateShortURL(_ context.Context, model *ShortURLModel) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+
+		current, err := getBoltShortURL(urls, model.ShortID)
+		if err != nil {
+			return err
+		}
+
+		if current == nil || current.Owner != model.Owner {
+			return ErrNotFound
+		}
+
+		updated := *model
+		updated.Clicks = current.Clicks
+		updated.VariantClicks = current.VariantClicks
+		updated.Status = current.Status
+		updated.StatusMessage = current.StatusMessage
+
+		data, err := bson.Marshal(&updated)
+		if err != nil {
+			return err
+		}
+
+		return urls.Put([]byte(model.ShortID), data)
+	})
+}
+
+func (s *BoltStore) UpdateShortURLStatus(_ context.Context, model *ShortURLModel, admin bool) (*ShortURLModel, error) {
+	var updated *ShortURLModel
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+
+		current, err := getBoltShortURL(urls, model.ShortID)
+		if err != nil {
+			return err
+		}
+
+		if current == nil || !admin && current.Owner != model.Owner {
+			return ErrNotFound
+		}
+
+		if !admin && current.Status == StatusBlocked {
+			return ErrBlocked
+		}
+
+		current.Status = model.Status
+		current.StatusMessage = model.StatusMessage
+		current.UpdatedAt = model.UpdatedAt
+
+		data, err := bson.Marshal(current)
+		if err != nil {
+			return err
+		}
+
+		updated = current
+
+		return urls.Put([]byte(model.ShortID), data)
+	})
+	if err != nil {
+		return new(ShortURLModel), err
+	}
+
+	return updated, nil
+}
+
+func (s *BoltStore) PurgeExpired(_ context.Context, before time.Time) ([]string, error) {
+	var shortIDs []string
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+		index := tx.Bucket([]byte(bucketShortURLsByOwner))
+
+		var expired []ShortURLModel
+
+		err := urls.ForEach(func(_, data []byte) error {
+			model := new(ShortURLModel)
+
+			err := bson.Unmarshal(data, model)
+			if err != nil {
+				return err
+			}
+
+			if model.ExpiresAt != nil && model.ExpiresAt.Before(before) {
+				expired = append(expired, *model)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, model := range expired {
+			err = urls.Delete([]byte(model.ShortID))
+			if err != nil {
+				return err
+			}
+
+			err = index.Delete(ownerKey(model.Owner, model.ShortID))
+			if err != nil {
+				return err
+			}
+
+			err = deleteBoltVersions(tx, model.ShortID)
+			if err != nil {
+				return err
+			}
+
+			shortIDs = append(shortIDs, model.ShortID)
+		}
+
+		return nil
+	})
+
+	return shortIDs, err
+}
+
+func (s *BoltStore) ShortIDs(_ context.Context, fn func(shortID string) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(CollectionShortURLs)).ForEach(func(k, _ []byte) error {
+			return fn(string(k))
+		})
+	})
+}
+
+func (s *BoltStore) IncrementClicks(_ context.Context, shortID, variant string) (bool, error) {
+	var allowed bool
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket([]byte(CollectionShortURLs))
+
+		model, err := getBoltShortURL(urls, shortID)
+		if err != nil || model == nil {
+			return err
+		}
+
+		if model.MaxClicks > 0 && model.Clicks >= model.MaxClicks {
+			return nil
+		}
+
+		allowed = true
+		model.Clicks++
+
+		if variant != "" {
+			model.VariantClicks = incrementVariantClicks(model.VariantClicks, variant)
+		}
+
+		data, err := bson.Marshal(model)
+		if err != nil {
+			return err
+		}
+
+		return urls.Put([]byte(shortID), data)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+func (s *BoltStore) AddVersion(_ context.Context, model *VersionModel) error {
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(CollectionVersions)).Put(versionKey(model.ShortID, model.ID), data)
+	})
+}
+
+func (s *BoltStore) GetVersions(_ context.Context, shortID string) ([]VersionModel, error) {
+	var result []VersionModel
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := versionPrefix(shortID)
+
+		c := tx.Bucket([]byte(CollectionVersions)).Cursor()
+		for k, data := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, data = c.Next() {
+			var model VersionModel
+
+			err := bson.Unmarshal(data, &model)
+			if err != nil {
+				return err
+			}
+
+			result = append(result, model)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *BoltStore) GetVersion(_ context.Context, shortID string, id primitive.ObjectID) (*VersionModel, error) {
+	model := new(VersionModel)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(CollectionVersions)).Get(versionKey(shortID, id))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		return bson.Unmarshal(data, model)
+	})
+
+	return model, err
+}
+
+func (s *BoltStore) get(shortID string) (*ShortURLModel, error) {
+	var model *ShortURLModel
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+
+		model, err = getBoltShortURL(tx.Bucket([]byte(CollectionShortURLs)), shortID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, ErrNotFound
+	}
+
+	return model, nil
+}
+
+func getBoltShortURL(bucket *bolt.Bucket, shortID string) (*ShortURLModel, error) {
+	data := bucket.Get([]byte(shortID))
+	if data == nil {
+		return nil, nil
+	}
+
+	model := new(ShortURLModel)
+
+	err := bson.Unmarshal(data, model)
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func deleteBoltVersions(tx *bolt.Tx, shortID string) error {
+	versions := tx.Bucket([]byte(CollectionVersions))
+	prefix := versionPrefix(shortID)
+
+	var keys [][]byte
+
+	c := versions.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, k)
+	}
+
+	for _, k := range keys {
+		err := versions.Delete(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// versionKey return key of version, object ids start with time, so versions are ordered by creation
+func versionKey(shortID string, id primitive.ObjectID) []byte {
+	return append(versionPrefix(shortID), id[:]...)
+}
+
+func versionPrefix(shortID string) []byte {
+	key := make([]byte, 0, len(shortID)+len(ownerKeySeparator)+len(primitive.ObjectID{}))
+	key = append(key, shortID...)
+
+	return append(key, ownerKeySeparator...)
+}
+
+func ownerKey(owner primitive.ObjectID, shortID string) []byte {
+	key := make([]byte, 0, len(owner)+len(ownerKeySeparator)+len(shortID))
+	key = append(key, owner[:]...)
+	key = append(key, ownerKeySeparator...)
+
+	return append(key, shortID...)
+}
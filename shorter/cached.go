@@ -0,0 +1,258 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/brf.im/cache"
+	"github.com/InsideGallery/brf.im/events"
+)
+
+// CachedStore store decorator, which keep redirect targets in memory,
+// reject unknown short ids without store lookup and notify other instances about link mutations
+type CachedStore struct {
+	Store
+	redirects *cache.LRU[string, ShortURLModel]
+	negative  *cache.LRU[string, struct{}]
+	bloom     *cache.Bloom
+	publisher events.Publisher
+}
+
+// NewCachedStore return store with redirect cache in front of ResolveShortURL
+func NewCachedStore(store Store, redirects *cache.LRU[string, ShortURLModel]) *CachedStore {
+	return &CachedStore{
+		Store:     store,
+		redirects: redirects,
+		negative:  cache.NewLRU[string, struct{}](0, 0),
+	}
+}
+
+// Uncached return store under redirect cache, store is returned as is when it is not cached
+func Uncached(store Store) Store {
+	if cached, ok := store.(*CachedStore); ok {
+		return cached.Store
+	}
+
+	return store
+}
+
+// WithNegativeLookup set bloom filter of existing short ids and cache of unknown short ids,
+// bloom filter could be nil
+func (s *CachedStore) WithNegativeLookup(bloom *cache.Bloom, negative *cache.LRU[string, struct{}]) {
+	s.bloom = bloom
+	s.negative = negative
+}
+
+// WarmUp add all existing short ids to bloom filter
+func (s *CachedStore) WarmUp(ctx context.Context) error {
+	if s.bloom == nil {
+		return nil
+	}
+
+	return s.Store.ShortIDs(ctx, func(shortID string) error {
+		s.bloom.Add(shortID)
+		return nil
+	})
+}
+
+// RebuildBloom replace bloom filter by short ids existing in store, so links which create events were lost
+// are found again and removed links are no longer passed to store
+func (s *CachedStore) RebuildBloom(ctx context.Context) error {
+	if s.bloom == nil {
+		return nil
+	}
+
+	return s.bloom.Rebuild(func(add func(value string)) error {
+		return s.Store.ShortIDs(ctx, func(shortID string) error {
+			add(shortID)
+			return nil
+		})
+	})
+}
+
+// WithPublisher set publisher of link mutation events
+func (s *CachedStore) WithPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// HandleEvent evict links mutated by other instance
+func (s *CachedStore) HandleEvent(event events.LinkEvent) {
+	if event.Type == events.TypeCreate {
+		s.remember(event.ShortIDs...)
+	}
+
+	s.redirects.Delete(event.ShortIDs...)
+}
+
+// Negative return cache of unknown short ids
+func (s *CachedStore) Negative() *cache.LRU[string, struct{}] {
+	return s.negative
+}
+
+// Redirects return redirect cache
+func (s *CachedStore) Redirects() *cache.LRU[string, ShortURLModel] {
+	return s.redirects
+}
+
+func (s *CachedStore) ResolveShortURL(ctx context.Context, shortID string) (*ShortURLModel, error) {
+	if model, ok := s.redirects.Get(shortID); ok {
+		return &model, nil
+	}
+
+	if s.bloom != nil && !s.bloom.MayContain(shortID) {
+		return new(ShortURLModel), ErrNotFound
+	}
+
+	if _, ok := s.negative.Get(shortID); ok {
+		return new(ShortURLModel), ErrNotFound
+	}
+
+	// concurrent mutation could delete key while store is read, then loaded result is not cached
+	version, negativeVersion := s.redirects.Version(), s.negative.Version()
+
+	model, err := s.Store.ResolveShortURL(ctx, shortID)
+	if errors.Is(err, ErrNotFound) {
+		s.negative.SetIfVersion(strings.Clone(shortID), struct{}{}, negativeVersion)
+	}
+
+	if err != nil {
+		return model, err
+	}
+
+	// short id could reference request buffer, which is reused after handler returns
+	s.redirects.SetIfVersion(strings.Clone(shortID), *model, version)
+
+	return model, nil
+}
+
+func (s *CachedStore) UpdateShortURL(ctx context.Context, model *ShortURLModel) error {
+	err := s.Store.UpdateShortURL(ctx, model)
+	s.redirects.Delete(model.ShortID)
+
+	s.publish(ctx, events.LinkEvent{
+		Type:     events.TypeUpdate,
+		ShortIDs: []string{model.ShortID},
+		Owner:    model.Owner.Hex(),
+	})
+
+	return err
+}
+
+func (s *CachedStore) UpdateShortURLStatus(
+	ctx context.Context,
+	model *ShortURLModel,
+	admin bool,
+) (*ShortURLModel, error) {
+	updated, err := s.Store.UpdateShortURLStatus(ctx, model, admin)
+	s.redirects.Delete(model.ShortID)
+
+	s.publish(ctx, events.LinkEvent{
+		Type:     events.TypeUpdate,
+		ShortIDs: []string{model.ShortID},
+		Owner:    updated.Owner.Hex(),
+	})
+
+	return updated, err
+}
+
+func (s *CachedStore) PurgeExpired(ctx context.Context, before time.Time) ([]string, error) {
+	shortIDs, err := s.Store.PurgeExpired(ctx, before)
+	if len(shortIDs) == 0 {
+		return shortIDs, err
+	}
+
+	s.redirects.Delete(shortIDs...)
+
+	s.publish(ctx, events.LinkEvent{
+		Type:     events.TypeDelete,
+		ShortIDs: shortIDs,
+	})
+
+	return shortIDs, err
+}
+
+func (s *CachedStore) InsertShortURL(ctx context.Context, model *ShortURLModel) error {
+	if s.bloom != nil {
+		s.bloom.Add(model.ShortID) // before insert, so link is never rejected once it is stored
+	}
+
+	err := s.Store.InsertShortURL(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	// added again, as rebuild of bloom filter could start after first add and miss just stored link
+	s.remember(model.ShortID)
+
+	s.publish(ctx, events.LinkEvent{
+		Type:     events.TypeCreate,
+		ShortIDs: []string{model.ShortID},
+		Owner:    model.Owner.Hex(),
+	})
+
+	return nil
+}
+
+func (s *CachedStore) RemoveShortURL(ctx context.Context, shortID string, owner primitive.ObjectID) error {
+	err := s.Store.RemoveShortURL(ctx, shortID, owner)
+	s.redirects.Delete(shortID)
+
+	s.publish(ctx, events.LinkEvent{
+		Type:     events.TypeDelete,
+		ShortIDs: []string{shortID},
+		Owner:    owner.Hex(),
+	})
+
+	return err
+}
+
+func (s *CachedStore) RemoveOwner(ctx context.Context, id primitive.ObjectID) error {
+	urls, err := s.Store.GetShortURLs(ctx, id, ShortURLFilter{})
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.RemoveOwner(ctx, id)
+
+	shortIDs := make([]string, len(urls))
+	for i, u := range urls {
+		shortIDs[i] = u.ShortID
+	}
+
+	s.redirects.Delete(shortIDs...)
+
+	s.publish(ctx, events.LinkEvent{
+		Type:     events.TypeOwnerRemove,
+		ShortIDs: shortIDs,
+		Owner:    id.Hex(),
+	})
+
+	return err
+}
+
+// remember mark short ids as existing
+func (s *CachedStore) remember(shortIDs ...string) {
+	if s.bloom != nil {
+		for _, shortID := range shortIDs {
+			s.bloom.Add(shortID)
+		}
+	}
+
+	s.negative.Delete(shortIDs...)
+}
+
+func (s *CachedStore) publish(ctx context.Context, event events.LinkEvent) {
+	if s.publisher == nil {
+		return
+	}
+
+	err := s.publisher.Publish(ctx, event)
+	if err != nil {
+		slog.Error("Error publishing link event", "err", err, "type", event.Type)
+	}
+}
@@ -0,0 +1,210 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/brf.im/cache"
+	"github.com/InsideGallery/brf.im/events"
+	"github.com/InsideGallery/core/testutils"
+)
+
+// runNATSServer start embedded NATS server on random port
+func runNATSServer(t *testing.T) string {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	testutils.Equal(t, err, nil)
+
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+
+	testutils.Equal(t, ns.ReadyForConnections(5*time.Second), true)
+
+	return ns.ClientURL()
+}
+
+// newEventStore return cached store of instance, which publish and receive link events over NATS
+func newEventStore(t *testing.T, addr, instance string, store Store) *CachedStore {
+	t.Helper()
+
+	conn, err := nats.Connect(addr)
+	testutils.Equal(t, err, nil)
+	t.Cleanup(conn.Close)
+
+	cached := NewCachedStore(store, cache.NewLRU[string, ShortURLModel](100, time.Hour))
+	bus := events.NewNATSBus(conn, events.DefaultSubject, instance)
+
+	_, err = bus.Subscribe(cached.HandleEvent)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, conn.Flush(), nil)
+
+	cached.WithPublisher(bus)
+
+	return cached
+}
+
+// eventually wait until resolved url of short id match given url, empty url means link is not found
+func eventually(t *testing.T, store *CachedStore, shortID, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		model, err := store.ResolveShortURL(context.Background(), shortID)
+		if errors.Is(err, ErrNotFound) && want == "" || err == nil && model.URL == want {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("short url %s is not evicted: url %q, err %v, want %q", shortID, model.URL, err, want)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCachedStoreEvents(t *testing.T) {
+	ctx := context.Background()
+	addr := runNATSServer(t)
+	store := NewMemoryStore()
+
+	first := newEventStore(t, addr, "first", store)
+	second := newEventStore(t, addr, "second", store)
+
+	owner := primitive.NewObjectID()
+	model := &ShortURLModel{ShortID: "events", Owner: owner, URL: "https://example.com/old"}
+
+	// second instance remember unknown short id, create event must drop it from negative cache
+	_, err := second.ResolveShortURL(ctx, model.ShortID)
+	testutils.Equal(t, errors.Is(err, ErrNotFound), true)
+
+	testutils.Equal(t, first.InsertShortURL(ctx, model), nil)
+	eventually(t, second, model.ShortID, "https://example.com/old")
+
+	// second instance keep link in redirect cache, update event must evict it
+	model.URL = "https://example.com/new"
+	testutils.Equal(t, first.UpdateShortURL(ctx, model), nil)
+	eventually(t, second, model.ShortID, "https://example.com/new")
+
+	testutils.Equal(t, first.RemoveShortURL(ctx, model.ShortID, owner), nil)
+	eventually(t, second, model.ShortID, "")
+}
+
+// countingStore count lookups which reach store
+type countingStore struct {
+	Store
+	lookups int
+}
+
+func (s *countingStore) ResolveShortURL(ctx context.Context, shortID string) (*ShortURLModel, error) {
+	s.lookups++
+	return s.Store.ResolveShortURL(ctx, shortID)
+}
+
+func TestCachedStoreBloom(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{Store: NewMemoryStore()}
+	owner := primitive.NewObjectID()
+
+	existing := &ShortURLModel{ShortID: "existing", Owner: owner, URL: "https://example.com"}
+	testutils.Equal(t, store.InsertShortURL(ctx, existing), nil)
+
+	bloom := cache.NewBloom(100, 0.01)
+	cached := NewCachedStore(store, cache.NewLRU[string, ShortURLModel](0, 0))
+	cached.WithNegativeLookup(bloom, cache.NewLRU[string, struct{}](100, time.Hour))
+	testutils.Equal(t, cached.WarmUp(ctx), nil)
+
+	resolved, err := cached.ResolveShortURL(ctx, existing.ShortID)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, resolved.URL, existing.URL)
+	testutils.Equal(t, store.lookups, 1)
+
+	// unknown short id is rejected by filter without store lookup
+	_, err = cached.ResolveShortURL(ctx, "unknown")
+	testutils.Equal(t, errors.Is(err, ErrNotFound), true)
+	testutils.Equal(t, store.lookups, 1)
+
+	created := &ShortURLModel{ShortID: "created", Owner: owner, URL: "https://example.com/created"}
+	testutils.Equal(t, cached.InsertShortURL(ctx, created), nil)
+
+	resolved, err = cached.ResolveShortURL(ctx, created.ShortID)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, resolved.URL, created.URL)
+
+	// link created by other instance, which create event was lost, is found after rebuild
+	missed := &ShortURLModel{ShortID: "missed", Owner: owner, URL: "https://example.com/missed"}
+	testutils.Equal(t, store.InsertShortURL(ctx, missed), nil)
+
+	_, err = cached.ResolveShortURL(ctx, missed.ShortID)
+	testutils.Equal(t, errors.Is(err, ErrNotFound), true)
+
+	testutils.Equal(t, store.RemoveShortURL(ctx, existing.ShortID, owner), nil)
+	testutils.Equal(t, cached.RebuildBloom(ctx), nil)
+
+	resolved, err = cached.ResolveShortURL(ctx, missed.ShortID)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, resolved.URL, missed.URL)
+	testutils.Equal(t, bloom.MayContain(created.ShortID), true)
+	testutils.Equal(t, bloom.MayContain(existing.ShortID), false)
+}
+
+// blockingStore pause ResolveShortURL until released, so test could mutate link while it is loaded
+type blockingStore struct {
+	Store
+	loaded  chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) ResolveShortURL(ctx context.Context, shortID string) (*ShortURLModel, error) {
+	model, err := s.Store.ResolveShortURL(ctx, shortID)
+
+	if s.loaded != nil {
+		close(s.loaded)
+		<-s.release
+	}
+
+	return model, err
+}
+
+func TestCachedStoreConcurrentUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := &blockingStore{Store: NewMemoryStore()}
+	cached := NewCachedStore(store, cache.NewLRU[string, ShortURLModel](100, time.Hour))
+
+	model := &ShortURLModel{ShortID: "race", Owner: primitive.NewObjectID(), URL: "https://example.com/old"}
+	testutils.Equal(t, cached.InsertShortURL(ctx, model), nil)
+
+	store.loaded = make(chan struct{})
+	store.release = make(chan struct{})
+
+	done := make(chan string)
+
+	go func() {
+		resolved, err := cached.ResolveShortURL(ctx, model.ShortID)
+		testutils.Equal(t, err, nil)
+
+		done <- resolved.URL
+	}()
+
+	// update happen after old link is loaded, but before it is cached
+	<-store.loaded
+
+	updated := *model
+	updated.URL = "https://example.com/new"
+	testutils.Equal(t, cached.UpdateShortURL(ctx, &updated), nil)
+
+	store.loaded = nil
+	close(store.release)
+	testutils.Equal(t, <-done, "https://example.com/old")
+
+	resolved, err := cached.ResolveShortURL(ctx, model.ShortID)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, resolved.URL, "https://example.com/new")
+}
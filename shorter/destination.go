@@ -0,0 +1,144 @@
+package shorter
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/InsideGallery/brf.im/geoip"
+	"github.com/InsideGallery/brf.im/rules"
+	"github.com/InsideGallery/brf.im/useragent"
+)
+
+var (
+	ErrInvalidPlatforms error = errors.New("error invalid platform overrides")
+	ErrInvalidCountries error = errors.New("error invalid country overrides")
+)
+
+// Visitor describe client opening short url
+type Visitor struct {
+	Client  useragent.Client
+	Country string
+	Variant string
+	Request rules.Request
+}
+
+// NewVisitor return visitor of request, country is known only when geoip is enabled
+func NewVisitor(c *fiber.Ctx) Visitor {
+	return Visitor{
+		Client:  useragent.Classify(c.Get(fiber.HeaderUserAgent)),
+		Country: geoip.GetCountry(c),
+		Request: rules.Request{
+			Time:   time.Now(),
+			Header: func(name string) string { return c.Get(name) },
+			Query:  func(name string) string { return c.Query(name) },
+		},
+	}
+}
+
+// Destination return url for visitor and name of variant when url is taken from variant.
+// Routing rules are checked first (in time zone of link), then overrides in order: platform, country,
+// device class, then variant chosen for visitor and main url are used
+func (m *ShortURLModel) Destination(v Visitor) (string, string) {
+	if len(m.Rules) > 0 {
+		req := v.Request
+		req.Time = req.Time.In(m.Location())
+
+		if destination, ok := rules.Evaluate(m.Rules, req); ok {
+			return destination, ""
+		}
+	}
+
+	if destination, ok := m.Platforms[v.Client.Platform]; ok {
+		return destination, ""
+	}
+
+	if destination, ok := m.Countries[v.Country]; ok && v.Country != "" {
+		return destination, ""
+	}
+
+	if destination, ok := m.Platforms[v.Client.Device]; ok {
+		return destination, ""
+	}
+
+	if variant, ok := m.GetVariant(v.Variant); ok {
+		return variant.URL, variant.Name
+	}
+
+	return m.URL, ""
+}
+
+// ValidateRules check rules could be evaluated and their urls are valid
+func ValidateRules(list []rules.Rule) error {
+	err := rules.Validate(list)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range list {
+		if ValidateURL(rule.URL) != nil {
+			return rules.ErrInvalidRules
+		}
+	}
+
+	return nil
+}
+
+// ValidateURL check url is absolute http or https url with host,
+// other schemes (javascript:, data:) would run script of owner on redirect pages
+func ValidateURL(rawURL string) error {
+	if !isHTTPLocation(rawURL) {
+		return ErrInvalidURL
+	}
+
+	return nil
+}
+
+func isHTTPLocation(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+
+	return scheme == "http" || scheme == "https"
+}
+
+// ValidatePlatforms check overrides are keyed by known platform or device class and point to valid urls
+func ValidatePlatforms(platforms map[string]string) error {
+	for key, destination := range platforms {
+		if !useragent.IsKnownKey(key) || ValidateURL(destination) != nil {
+			return ErrInvalidPlatforms
+		}
+	}
+
+	return nil
+}
+
+// NormalizeCountries return overrides keyed by upper-case ISO 3166-1 alpha-2 country codes
+func NormalizeCountries(countries map[string]string) (map[string]string, error) {
+	if len(countries) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(countries))
+
+	for code, destination := range countries {
+		code = strings.ToUpper(code)
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, ErrInvalidCountries
+		}
+
+		if ValidateURL(destination) != nil {
+			return nil, ErrInvalidCountries
+		}
+
+		result[code] = destination
+	}
+
+	return result, nil
+}
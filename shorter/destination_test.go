@@ -0,0 +1,32 @@
+package shorter
+
+import (
+	"testing"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestValidateURL(t *testing.T) {
+	cases := []struct {
+		url   string
+		valid bool
+	}{
+		{url: "https://example.com/path?q=1", valid: true},
+		{url: "http://example.com", valid: true},
+		{url: "HTTPS://example.com", valid: true},
+		{url: "javascript:alert(1)"},
+		{url: "JavaScript://example.com/%0Aalert(1)"},
+		{url: "data:text/html,<script>alert(1)</script>"},
+		{url: "ftp://example.com/file"},
+		{url: "//example.com/path"},
+		{url: "/path"},
+		{url: "https://"},
+		{url: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			testutils.Equal(t, ValidateURL(tc.url) == nil, tc.valid)
+		})
+	}
+}
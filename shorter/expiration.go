@@ -0,0 +1,89 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+var ErrInvalidExpiration error = errors.New("error invalid expiration")
+
+// ExpirationRequest describe link expiration as absolute time or as ttl (like `72h`),
+// link never expires when both are empty
+type ExpirationRequest struct {
+	ExpiresAt *time.Time `json:"expiresAt"`
+	TTL       string     `json:"ttl"`
+}
+
+// GetExpiresAt return expiration time, which should be in future
+func (req ExpirationRequest) GetExpiresAt(now time.Time) (*time.Time, error) {
+	if req.ExpiresAt != nil && req.TTL != "" {
+		return nil, ErrInvalidExpiration
+	}
+
+	expiresAt := req.ExpiresAt
+
+	if req.TTL != "" {
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil {
+			return nil, errors.Join(ErrInvalidExpiration, err)
+		}
+
+		t := now.Add(ttl)
+		expiresAt = &t
+	}
+
+	if expiresAt == nil {
+		return nil, nil
+	}
+
+	if !expiresAt.After(now) {
+		return nil, ErrInvalidExpiration
+	}
+
+	t := expiresAt.UTC()
+
+	return &t, nil
+}
+
+// RunPurgeExpired remove links expired more than grace period ago, every interval until context is done
+func RunPurgeExpired(ctx context.Context, store Store, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			shortIDs, err := store.PurgeExpired(ctx, time.Now().Add(-grace))
+			if err != nil {
+				slog.Error("Error purging expired short urls", "err", err)
+				continue
+			}
+
+			if len(shortIDs) > 0 {
+				slog.Info("Expired short urls purged", "count", len(shortIDs))
+			}
+		}
+	}
+}
+
+// RunBloomRebuild rebuild bloom filter of store every interval until context is done
+func RunBloomRebuild(ctx context.Context, store *CachedStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := store.RebuildBloom(ctx)
+			if err != nil {
+				slog.Error("Error rebuilding bloom filter", "err", err)
+			}
+		}
+	}
+}
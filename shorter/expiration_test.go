@@ -0,0 +1,47 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestPurgeExpired(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	expired := now.Add(-time.Hour)
+	active := now.Add(time.Hour)
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			owner := primitive.NewObjectID()
+
+			links := []*ShortURLModel{
+				{ShortID: "expired", Owner: owner, URL: "https://example.com/expired", ExpiresAt: &expired},
+				{ShortID: "active", Owner: owner, URL: "https://example.com/active", ExpiresAt: &active},
+				{ShortID: "forever", Owner: owner, URL: "https://example.com/forever"},
+			}
+
+			for _, link := range links {
+				testutils.Equal(t, store.InsertShortURL(ctx, link), nil)
+			}
+
+			shortIDs, err := store.PurgeExpired(ctx, now)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, shortIDs, []string{"expired"})
+
+			_, err = store.GetShortURL(ctx, "expired", owner)
+			testutils.Equal(t, errors.Is(err, ErrNotFound), true)
+
+			for _, shortID := range []string{"active", "forever"} {
+				_, err = store.GetShortURL(ctx, shortID, owner)
+				testutils.Equal(t, err, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/InsideGallery/core/utils"
+)
+
+var (
+	ErrUnknownGenerator error = errors.New("unknown short id generator")
+	ErrInvalidAlphabet  error = errors.New("invalid alphabet")
+	ErrInvalidMinLength error = errors.New("invalid min length")
+)
+
+const (
+	GeneratorRandom  = "random"
+	GeneratorCounter = "counter"
+	GeneratorHashids = "hashids"
+	GeneratorWords   = "words"
+
+	AlphabetDefault     = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	AlphabetUnambiguous = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz23456789" // without 0/O/o, 1/l/I
+
+	SequenceShortID = "short_id"
+
+	minAlphabetLength        = 2
+	minHashidsAlphabetLength = 16
+	maxMinLength             = 32
+	defaultRandomLength      = 7
+	lotteryMultiplier        = 2654435761
+)
+
+var alphabetPresets = map[string]string{
+	"default":     AlphabetDefault,
+	"unambiguous": AlphabetUnambiguous,
+}
+
+var defaultGeneratorConfig = GetGeneratorConfigFromEnv()
+
+// ShortIDGenerator generate candidate short ids, attempt is number of collisions already happened
+type ShortIDGenerator interface {
+	Generate(ctx context.Context, prefix string, attempt int) (string, error)
+}
+
+// Sequencer return next value of named sequence, values must be unique across instances
+type Sequencer interface {
+	NextSequence(ctx context.Context, name string) (uint64, error)
+}
+
+// GeneratorConfig describe short id generation strategy
+type GeneratorConfig struct {
+	Strategy  string `bson:"strategy,omitempty" json:"strategy,omitempty"`
+	Alphabet  string `bson:"alphabet,omitempty" json:"alphabet,omitempty"`
+	MinLength int    `bson:"min_length,omitempty" json:"minLength,omitempty"`
+}
+
+// GetGeneratorConfigFromEnv return deployment default generator config
+func GetGeneratorConfigFromEnv() GeneratorConfig {
+	minLength, err := strconv.Atoi(os.Getenv("SHORT_ID_MIN_LENGTH"))
+	if err != nil {
+		minLength = 0
+	}
+
+	return GeneratorConfig{
+		Strategy:  os.Getenv("SHORT_ID_STRATEGY"),
+		Alphabet:  os.Getenv("SHORT_ID_ALPHABET"),
+		MinLength: minLength,
+	}
+}
+
+// Merge return config where empty fields are taken from given defaults
+func (cfg GeneratorConfig) Merge(defaults GeneratorConfig) GeneratorConfig {
+	if cfg.Strategy == "" {
+		cfg.Strategy = defaults.Strategy
+	}
+
+	if cfg.Alphabet == "" {
+		cfg.Alphabet = defaults.Alphabet
+	}
+
+	if cfg.MinLength == 0 {
+		cfg.MinLength = defaults.MinLength
+	}
+
+	return cfg
+}
+
+// GetAlphabet return alphabet resolved from preset name or given characters
+func (cfg GeneratorConfig) GetAlphabet() string {
+	if cfg.Alphabet == "" {
+		return AlphabetDefault
+	}
+
+	if alphabet, ok := alphabetPresets[cfg.Alphabet]; ok {
+		return alphabet
+	}
+
+	return cfg.Alphabet
+}
+
+// Validate check config is usable
+func (cfg GeneratorConfig) Validate() error {
+	switch cfg.Strategy {
+	case "", GeneratorRandom, GeneratorCounter, GeneratorHashids, GeneratorWords:
+	default:
+		return fmt.Errorf("%w: strategy %s", ErrUnknownGenerator, cfg.Strategy)
+	}
+
+	if cfg.MinLength < 0 || cfg.MinLength > maxMinLength {
+		return ErrInvalidMinLength
+	}
+
+	alphabet := cfg.GetAlphabet()
+
+	seen := map[rune]struct{}{}
+	for _, r := range alphabet {
+		if _, ok := seen[r]; ok || !isAlphanumeric(r) {
+			return ErrInvalidAlphabet
+		}
+
+		seen[r] = struct{}{}
+	}
+
+	if len(alphabet) < minAlphabetLength ||
+		(cfg.Strategy == GeneratorHashids && len(alphabet) < minHashidsAlphabetLength) {
+		return ErrInvalidAlphabet
+	}
+
+	return nil
+}
+
+// NewShortIDGenerator return generator for given config
+func NewShortIDGenerator(cfg GeneratorConfig, seq Sequencer) (ShortIDGenerator, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	alphabet := cfg.GetAlphabet()
+
+	switch cfg.Strategy {
+	case GeneratorCounter:
+		return &CounterGenerator{seq: seq, alphabet: alphabet, minLength: cfg.MinLength}, nil
+	case GeneratorHashids:
+		return NewHashidsGenerator(seq, alphabet, os.Getenv("SHORT_ID_SALT"), cfg.MinLength), nil
+	case GeneratorWords:
+		return &WordsGenerator{minLength: cfg.MinLength}, nil
+	}
+
+	return &RandomGenerator{alphabet: alphabet, minLength: cfg.MinLength}, nil
+}
+
+func withPrefix(prefix, shortID string) string {
+	if prefix == "" {
+		return shortID
+	}
+
+	return strings.Join([]string{prefix, shortID}, "-")
+}
+
+// RandomGenerator generate random ids, every 3 collisions id become one character longer
+type RandomGenerator struct {
+	alphabet  string
+	minLength int
+}
+
+func (g *RandomGenerator) Generate(_ context.Context, prefix string, attempt int) (string, error) {
+	var rawShortID []byte
+
+	if g.alphabet == AlphabetDefault && g.minLength == 0 {
+		tinyID, err := utils.GetTinyID()
+		if err != nil {
+			return "", err
+		}
+
+		rawShortID = tinyID
+		if prefix != "" {
+			rawShortID = rawShortID[2:]
+		}
+	} else {
+		rawShortID = getRandomCharsFrom(g.alphabet, max(g.minLength, defaultRandomLength))
+	}
+
+	if attempt >= defaultRetries {
+		rawShortID = append(rawShortID, getRandomCharsFrom(g.alphabet, attempt/defaultRetries)...)
+	}
+
+	return withPrefix(prefix, string(rawShortID)), nil
+}
+
+// CounterGenerator encode value of atomic sequence with alphabet
+type CounterGenerator struct {
+	seq       Sequencer
+	alphabet  string
+	minLength int
+}
+
+func (g *CounterGenerator) Generate(ctx context.Context, prefix string, _ int) (string, error) {
+	n, err := g.seq.NextSequence(ctx, SequenceShortID)
+	if err != nil {
+		return "", err
+	}
+
+	shortID := encodeNumber(n, g.alphabet)
+	if len(shortID) < g.minLength {
+		// encoded numbers never start with zero digit, so left padding keep ids unique
+		shortID = strings.Repeat(g.alphabet[:1], g.minLength-len(shortID)) + shortID
+	}
+
+	return withPrefix(prefix, shortID), nil
+}
+
+// HashidsGenerator encode value of atomic sequence with salted alphabet,
+// so neighbour values do not look sequential
+type HashidsGenerator struct {
+	seq       Sequencer
+	alphabet  string
+	guard     byte
+	salt      string
+	minLength int
+}
+
+// NewHashidsGenerator return new hashids-style generator
+func NewHashidsGenerator(seq Sequencer, alphabet, salt string, minLength int) *HashidsGenerator {
+	shuffled := consistentShuffle(alphabet, salt)
+
+	return &HashidsGenerator{
+		seq:       seq,
+		alphabet:  shuffled[1:],
+		guard:     shuffled[0],
+		salt:      salt,
+		minLength: minLength,
+	}
+}
+
+func (g *HashidsGenerator) Generate(ctx context.Context, prefix string, _ int) (string, error) {
+	n, err := g.seq.NextSequence(ctx, SequenceShortID)
+	if err != nil {
+		return "", err
+	}
+
+	return withPrefix(prefix, g.Encode(n)), nil
+}
+
+// Encode return obfuscated representation of number
+func (g *HashidsGenerator) Encode(n uint64) string {
+	lottery := g.alphabet[(n*lotteryMultiplier)%uint64(len(g.alphabet))]
+	alphabet := consistentShuffle(g.alphabet, string(lottery)+g.salt)
+
+	result := []byte{lottery}
+	result = append(result, encodeNumber(n, alphabet)...)
+
+	if len(result) < g.minLength {
+		// guard character is never used by encoded value, so it mark start of padding
+		result = append(result, g.guard)
+		for i := 0; len(result) < g.minLength; i++ {
+			result = append(result, alphabet[(n+uint64(i))%uint64(len(alphabet))])
+		}
+	}
+
+	return string(result)
+}
+
+// WordsGenerator generate pronounceable ids from dictionary words
+type WordsGenerator struct {
+	minLength int
+}
+
+func (g *WordsGenerator) Generate(_ context.Context, prefix string, attempt int) (string, error) {
+	parts := []string{
+		adjectives[rand.IntN(len(adjectives))], //nolint:gosec
+		nouns[rand.IntN(len(nouns))],           //nolint:gosec
+	}
+
+	for i := 0; i < attempt/defaultRetries || len(strings.Join(parts, "-")) < g.minLength; i++ {
+		parts = append(parts, nouns[rand.IntN(len(nouns))]) //nolint:gosec
+	}
+
+	return withPrefix(prefix, strings.Join(parts, "-")), nil
+}
+
+func encodeNumber(n uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+
+	var result []byte
+
+	for {
+		result = append([]byte{alphabet[n%base]}, result...)
+		n /= base
+
+		if n == 0 {
+			break
+		}
+	}
+
+	return string(result)
+}
+
+// consistentShuffle shuffle alphabet deterministically by salt
+func consistentShuffle(alphabet, salt string) string {
+	result := []byte(alphabet)
+	if salt == "" {
+		return alphabet
+	}
+
+	for i, v, p := len(result)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		result[i], result[j] = result[j], result[i]
+		v++
+	}
+
+	return string(result)
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func getRandomCharsFrom(alphabet string, n int) []byte {
+	result := make([]byte, n)
+	for i := 0; i < n; i++ {
+		result[i] = alphabet[rand.IntN(len(alphabet))] //nolint:gosec
+	}
+
+	return result
+}
+
+var adjectives = []string{
+	"able", "bold", "brave", "bright", "calm", "clever", "cool", "crisp",
+	"daring", "eager", "early", "easy", "fair", "fancy", "fast", "fine",
+	"fresh", "gentle", "glad", "golden", "grand", "happy", "honest", "jolly",
+	"keen", "kind", "lively", "lucky", "merry", "mighty", "modern", "neat",
+	"noble", "proud", "quick", "quiet", "rapid", "ready", "royal", "rustic",
+	"sharp", "shiny", "silent", "simple", "smart", "solid", "sunny", "super",
+	"sweet", "swift", "tidy", "tiny", "vivid", "warm", "wise", "witty",
+}
+
+var nouns = []string{
+	"apple", "arrow", "badge", "beach", "bird", "boat", "bridge", "canyon",
+	"cloud", "comet", "coral", "daisy", "dawn", "delta", "eagle", "ember",
+	"falcon", "field", "forest", "garden", "harbor", "island", "jungle", "lake",
+	"lemon", "lion", "maple", "meadow", "moon", "mountain", "ocean", "olive",
+	"orbit", "panda", "pearl", "pepper", "pine", "planet", "pony", "river",
+	"rocket", "sail", "shell", "sky", "spark", "star", "stone", "storm",
+	"sun", "tiger", "tulip", "valley", "wave", "willow", "wind", "wolf",
+}
@@ -0,0 +1,215 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+// generateIDs return n ids generated without collisions
+func generateIDs(t *testing.T, generator ShortIDGenerator, prefix string, n int) []string {
+	t.Helper()
+
+	ids := make([]string, n)
+
+	for i := range ids {
+		id, err := generator.Generate(context.Background(), prefix, 0)
+		testutils.Equal(t, err, nil)
+
+		ids[i] = id
+	}
+
+	return ids
+}
+
+func TestGeneratorConfigValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  GeneratorConfig
+		err  error
+	}{
+		{name: "default", cfg: GeneratorConfig{}},
+		{name: "counter with preset", cfg: GeneratorConfig{Strategy: GeneratorCounter, Alphabet: "unambiguous"}},
+		{name: "hashids", cfg: GeneratorConfig{Strategy: GeneratorHashids, MinLength: maxMinLength}},
+		{name: "words", cfg: GeneratorConfig{Strategy: GeneratorWords, MinLength: 12}},
+		{name: "binary alphabet", cfg: GeneratorConfig{Strategy: GeneratorCounter, Alphabet: "ab"}},
+		{name: "unknown strategy", cfg: GeneratorConfig{Strategy: "uuid"}, err: ErrUnknownGenerator},
+		{name: "negative min length", cfg: GeneratorConfig{MinLength: -1}, err: ErrInvalidMinLength},
+		{name: "too long min length", cfg: GeneratorConfig{MinLength: maxMinLength + 1}, err: ErrInvalidMinLength},
+		{name: "one character alphabet", cfg: GeneratorConfig{Alphabet: "a"}, err: ErrInvalidAlphabet},
+		{name: "repeated characters", cfg: GeneratorConfig{Alphabet: "abca"}, err: ErrInvalidAlphabet},
+		{name: "not alphanumeric", cfg: GeneratorConfig{Alphabet: "ab-"}, err: ErrInvalidAlphabet},
+		{
+			name: "short hashids alphabet",
+			cfg:  GeneratorConfig{Strategy: GeneratorHashids, Alphabet: "abcdef"},
+			err:  ErrInvalidAlphabet,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			testutils.Equal(t, errors.Is(err, tc.err), true)
+
+			_, err = NewShortIDGenerator(tc.cfg, NewMemoryStore())
+			testutils.Equal(t, errors.Is(err, tc.err), true)
+		})
+	}
+}
+
+func TestGeneratorConfigMerge(t *testing.T) {
+	defaults := GeneratorConfig{Strategy: GeneratorCounter, Alphabet: "unambiguous", MinLength: 5}
+
+	testutils.Equal(t, GeneratorConfig{}.Merge(defaults), defaults)
+	testutils.Equal(t,
+		GeneratorConfig{Strategy: GeneratorWords}.Merge(defaults),
+		GeneratorConfig{Strategy: GeneratorWords, Alphabet: "unambiguous", MinLength: 5},
+	)
+}
+
+func TestGeneratorAlphabet(t *testing.T) {
+	testutils.Equal(t, GeneratorConfig{}.GetAlphabet(), AlphabetDefault)
+	testutils.Equal(t, GeneratorConfig{Alphabet: "default"}.GetAlphabet(), AlphabetDefault)
+	testutils.Equal(t, GeneratorConfig{Alphabet: "unambiguous"}.GetAlphabet(), AlphabetUnambiguous)
+	testutils.Equal(t, GeneratorConfig{Alphabet: "abc"}.GetAlphabet(), "abc")
+
+	for _, strategy := range []string{GeneratorRandom, GeneratorCounter, GeneratorHashids} {
+		t.Run(strategy, func(t *testing.T) {
+			cfg := GeneratorConfig{Strategy: strategy, Alphabet: "unambiguous"}
+
+			generator, err := NewShortIDGenerator(cfg, NewMemoryStore())
+			testutils.Equal(t, err, nil)
+
+			for _, id := range generateIDs(t, generator, "", 2000) {
+				testutils.Equal(t, strings.ContainsAny(id, "0OoIl1"), false)
+			}
+		})
+	}
+}
+
+func TestGeneratorUniqueness(t *testing.T) {
+	const n = 20000
+
+	cases := []struct {
+		name      string
+		cfg       GeneratorConfig
+		minLength int
+	}{
+		// default random ids are tiny ids of variable length
+		{name: "random", cfg: GeneratorConfig{Strategy: GeneratorRandom}, minLength: 1},
+		{name: "random min length", cfg: GeneratorConfig{Strategy: GeneratorRandom, MinLength: 10}, minLength: 10},
+		{name: "counter", cfg: GeneratorConfig{Strategy: GeneratorCounter}, minLength: 1},
+		{
+			name:      "counter min length",
+			cfg:       GeneratorConfig{Strategy: GeneratorCounter, Alphabet: "unambiguous", MinLength: 6},
+			minLength: 6,
+		},
+		{name: "hashids", cfg: GeneratorConfig{Strategy: GeneratorHashids}, minLength: 2},
+		{name: "hashids min length", cfg: GeneratorConfig{Strategy: GeneratorHashids, MinLength: 8}, minLength: 8},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator, err := NewShortIDGenerator(tc.cfg, NewMemoryStore())
+			testutils.Equal(t, err, nil)
+
+			seen := make(map[string]struct{}, n)
+
+			for _, id := range generateIDs(t, generator, "", n) {
+				_, duplicate := seen[id]
+				testutils.Equal(t, duplicate, false)
+				testutils.Equal(t, len(id) >= tc.minLength, true)
+
+				seen[id] = struct{}{}
+			}
+		})
+	}
+}
+
+func TestCounterGenerator(t *testing.T) {
+	generator, err := NewShortIDGenerator(GeneratorConfig{Strategy: GeneratorCounter, MinLength: 4}, NewMemoryStore())
+	testutils.Equal(t, err, nil)
+
+	// sequence start at 1, padding use zero digit of alphabet
+	testutils.Equal(t, generateIDs(t, generator, "", 3), []string{"AAAB", "AAAC", "AAAD"})
+	testutils.Equal(t, generateIDs(t, generator, "go", 1), []string{"go-AAAE"})
+
+	testutils.Equal(t, encodeNumber(0, AlphabetDefault), "A")
+	testutils.Equal(t, encodeNumber(62, AlphabetDefault), "BA")
+	testutils.Equal(t, encodeNumber(5, "01"), "101")
+}
+
+func TestHashidsGenerator(t *testing.T) {
+	first := NewHashidsGenerator(NewMemoryStore(), AlphabetDefault, "salt", 0)
+	same := NewHashidsGenerator(NewMemoryStore(), AlphabetDefault, "salt", 0)
+	other := NewHashidsGenerator(NewMemoryStore(), AlphabetDefault, "pepper", 0)
+
+	testutils.Equal(t, first.Encode(42), same.Encode(42))
+	testutils.Equal(t, first.Encode(42) == other.Encode(42), false)
+	testutils.Equal(t, first.Encode(42) == first.Encode(43), false)
+
+	padded := NewHashidsGenerator(NewMemoryStore(), AlphabetDefault, "salt", 10)
+
+	for n := range uint64(1000) {
+		id := padded.Encode(n)
+		testutils.Equal(t, len(id), 10)
+		// padding start with guard, so unpadded value is prefix of padded one
+		testutils.Equal(t, strings.HasPrefix(id, first.Encode(n)+string(padded.guard)), true)
+	}
+}
+
+func TestWordsGenerator(t *testing.T) {
+	generator := &WordsGenerator{minLength: 20}
+
+	for attempt := range 10 {
+		id, err := generator.Generate(context.Background(), "", attempt)
+		testutils.Equal(t, err, nil)
+		testutils.Equal(t, len(id) >= 20, true)
+
+		// every defaultRetries collisions add one more word
+		testutils.Equal(t, strings.Count(id, "-")+1 >= 2+attempt/defaultRetries, true)
+	}
+
+	id, err := (&WordsGenerator{}).Generate(context.Background(), "go", 0)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, strings.HasPrefix(id, "go-"), true)
+	testutils.Equal(t, strings.Count(id, "-"), 2)
+}
+
+// constantGenerator always return the same id
+type constantGenerator struct{}
+
+func (constantGenerator) Generate(context.Context, string, int) (string, error) {
+	return "taken", nil
+}
+
+func TestCreateShortURLRetries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	owner := primitive.NewObjectID()
+
+	_, err := CreateShortURL(ctx, store, constantGenerator{}, NewReservedWords(), "", &ShortURLModel{Owner: owner})
+	testutils.Equal(t, err, nil)
+
+	_, err = CreateShortURL(ctx, store, constantGenerator{}, NewReservedWords(), "", &ShortURLModel{Owner: owner})
+	testutils.Equal(t, err, ErrShortIDsExhausted)
+
+	// words collide often, but growing ids always find free one
+	generator := &WordsGenerator{}
+	seen := map[string]struct{}{}
+
+	for range 5000 {
+		id, err := CreateShortURL(ctx, store, generator, NewReservedWords(), "", &ShortURLModel{Owner: owner})
+		testutils.Equal(t, err, nil)
+
+		_, duplicate := seen[id]
+		testutils.Equal(t, duplicate, false)
+
+		seen[id] = struct{}{}
+	}
+}
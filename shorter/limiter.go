@@ -0,0 +1,89 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/InsideGallery/core/db/mongodb"
+)
+
+// MongoLimiterStorage keep limiter counters in mongodb, so limits are shared by all instances,
+// expired counters are removed by TTL index
+type MongoLimiterStorage struct {
+	ctx    context.Context
+	client *mongodb.MongoClient
+}
+
+type limiterEntry struct {
+	Key       string     `bson:"_id"`
+	Value     []byte     `bson:"value"`
+	ExpiresAt *time.Time `bson:"expires_at,omitempty"`
+}
+
+// LimiterStorage return limiter storage in the same database
+func (s *MongoStore) LimiterStorage(ctx context.Context) *MongoLimiterStorage {
+	return &MongoLimiterStorage{ctx: ctx, client: s.client}
+}
+
+func (s *MongoLimiterStorage) Get(key string) ([]byte, error) {
+	var entry limiterEntry
+
+	filter := bson.D{{Key: "_id", Value: key}}
+
+	err := s.client.Collection(CollectionLimits).FindOne(s.ctx, filter).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// TTL index remove expired documents with delay
+	if entry.ExpiresAt != nil && !time.Now().Before(*entry.ExpiresAt) {
+		return nil, nil
+	}
+
+	return entry.Value, nil
+}
+
+func (s *MongoLimiterStorage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+
+	entry := limiterEntry{Key: key, Value: val}
+
+	if exp > 0 {
+		expiresAt := time.Now().Add(exp)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	filter := bson.D{{Key: "_id", Value: key}}
+
+	_, err := s.client.Collection(CollectionLimits).ReplaceOne(s.ctx, filter, entry, options.Replace().SetUpsert(true))
+
+	return err
+}
+
+func (s *MongoLimiterStorage) Delete(key string) error {
+	_, err := s.client.Collection(CollectionLimits).DeleteOne(s.ctx, bson.D{{Key: "_id", Value: key}})
+
+	return err
+}
+
+func (s *MongoLimiterStorage) Reset() error {
+	_, err := s.client.Collection(CollectionLimits).DeleteMany(s.ctx, bson.D{})
+
+	return err
+}
+
+// Close do nothing, client is shared with store
+func (s *MongoLimiterStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,288 @@
+package shorter
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryStore concurrency-safe in-memory store
+type MemoryStore struct {
+	mu        sync.RWMutex
+	owners    map[primitive.ObjectID]OwnerModel
+	urls      map[string]ShortURLModel
+	versions  map[string][]VersionModel
+	sequences map[string]uint64
+}
+
+// NewMemoryStore return new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		owners:    map[primitive.ObjectID]OwnerModel{},
+		urls:      map[string]ShortURLModel{},
+		versions:  map[string][]VersionModel{},
+		sequences: map[string]uint64{},
+	}
+}
+
+func (s *MemoryStore) NextSequence(_ context.Context, name string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequences[name]++
+
+	return s.sequences[name], nil
+}
+
+func (s *MemoryStore) CreateOwner(_ context.Context, model *OwnerModel) (primitive.ObjectID, error) {
+	model.ID = primitive.NewObjectID()
+
+	s.mu.Lock()
+	s.owners[model.ID] = *model
+	s.mu.Unlock()
+
+	return model.ID, nil
+}
+
+func (s *MemoryStore) GetOwner(_ context.Context, id primitive.ObjectID) (*OwnerModel, error) {
+	s.mu.RLock()
+	model, exists := s.owners[id]
+	s.mu.RUnlock()
+
+	if !exists {
+		return new(OwnerModel), ErrNotFound
+	}
+
+	return &model, nil
+}
+
+func (s *MemoryStore) UpdateOwner(_ context.Context, model *OwnerModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.owners[model.ID]; !exists {
+		return ErrNotFound
+	}
+
+	s.owners[model.ID] = *model
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveOwner(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for shortID, model := range s.urls {
+		if model.Owner == id {
+			delete(s.urls, shortID)
+			delete(s.versions, shortID)
+		}
+	}
+
+	delete(s.owners, id)
+
+	return nil
+}
+
+func (s *MemoryStore) InsertShortURL(_ context.Context, model *ShortURLModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.urls[model.ShortID]; exists {
+		return ErrShortIDExists
+	}
+
+	s.urls[model.ShortID] = *model
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveShortURL(_ context.Context, shortID string, owner primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	model, exists := s.urls[shortID]
+	if !exists || model.Owner != owner {
+		return nil
+	}
+
+	delete(s.urls, shortID)
+	delete(s.versions, shortID)
+
+	return nil
+}
+
+func (s *MemoryStore) GetShortURLs(
+	_ context.Context,
+	owner primitive.ObjectID,
+	filter ShortURLFilter,
+) ([]ShortURLModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []ShortURLModel
+
+	for _, model := range s.urls {
+		if model.Owner == owner && filter.Match(&model) {
+			result = append(result, model)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *MemoryStore) GetShortURL(
+	_ context.Context,
+	shortID string,
+	owner primitive.ObjectID,
+) (*ShortURLModel, error) {
+	s.mu.RLock()
+	model, exists := s.urls[shortID]
+	s.mu.RUnlock()
+
+	if !exists || model.Owner != owner {
+		return new(ShortURLModel), ErrNotFound
+	}
+
+	return &model, nil
+}
+
+func (s *MemoryStore) ResolveShortURL(_ context.Context, shortID string) (*ShortURLModel, error) {
+	s.mu.RLock()
+	model, exists := s.urls[shortID]
+	s.mu.RUnlock()
+
+	if !exists {
+		return new(ShortURLModel), ErrNotFound
+	}
+
+	return &model, nil
+}
+
+func (s *MemoryStore) UpdateShortURL(_ context.Context, model *ShortURLModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, exists := s.urls[model.ShortID]
+	if !exists || current.Owner != model.Owner {
+		return ErrNotFound
+	}
+
+	updated := *model
+	updated.Clicks = current.Clicks
+	updated.VariantClicks = current.VariantClicks
+	updated.Status = current.Status
+	updated.StatusMessage = current.StatusMessage
+	s.urls[model.ShortID] = updated
+
+	return nil
+}
+
+func (s *MemoryStore) UpdateShortURLStatus(_ context.Context, model *ShortURLModel, admin bool) (*ShortURLModel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, exists := s.urls[model.ShortID]
+	if !exists || !admin && current.Owner != model.Owner {
+		return new(ShortURLModel), ErrNotFound
+	}
+
+	if !admin && current.Status == StatusBlocked {
+		return new(ShortURLModel), ErrBlocked
+	}
+
+	current.Status = model.Status
+	current.StatusMessage = model.StatusMessage
+	current.UpdatedAt = model.UpdatedAt
+	s.urls[model.ShortID] = current
+
+	return &current, nil
+}
+
+func (s *MemoryStore) PurgeExpired(_ context.Context, before time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var shortIDs []string
+
+	for shortID, model := range s.urls {
+		if model.ExpiresAt != nil && model.ExpiresAt.Before(before) {
+			shortIDs = append(shortIDs, shortID)
+			delete(s.urls, shortID)
+			delete(s.versions, shortID)
+		}
+	}
+
+	return shortIDs, nil
+}
+
+func (s *MemoryStore) ShortIDs(_ context.Context, fn func(shortID string) error) error {
+	s.mu.RLock()
+	shortIDs := make([]string, 0, len(s.urls))
+
+	for shortID := range s.urls {
+		shortIDs = append(shortIDs, shortID)
+	}
+	s.mu.RUnlock()
+
+	for _, shortID := range shortIDs {
+		err := fn(shortID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) IncrementClicks(_ context.Context, shortID, variant string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	model, exists := s.urls[shortID]
+	if !exists || (model.MaxClicks > 0 && model.Clicks >= model.MaxClicks) {
+		return false, nil
+	}
+
+	model.Clicks++
+	if variant != "" {
+		model.VariantClicks = incrementVariantClicks(model.VariantClicks, variant)
+	}
+
+	// keep stored key, given short id could reference reused request buffer
+	s.urls[model.ShortID] = model
+
+	return true, nil
+}
+
+func (s *MemoryStore) AddVersion(_ context.Context, model *VersionModel) error {
+	s.mu.Lock()
+	s.versions[model.ShortID] = append(s.versions[model.ShortID], *model)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *MemoryStore) GetVersions(_ context.Context, shortID string) ([]VersionModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.versions[shortID]), nil
+}
+
+func (s *MemoryStore) GetVersion(_ context.Context, shortID string, id primitive.ObjectID) (*VersionModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, model := range s.versions[shortID] {
+		if model.ID == id {
+			return &model, nil
+		}
+	}
+
+	return new(VersionModel), ErrNotFound
+}
@@ -0,0 +1,62 @@
+package shorter
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidMetadata error = errors.New("error invalid metadata")
+
+const (
+	maxTitleLength = 200
+	maxNotesLength = 2000
+	maxTags        = 20
+	maxTagLength   = 32
+)
+
+// ShortURLFilter describe filter of owner short urls, empty fields match everything
+type ShortURLFilter struct {
+	Tag string
+}
+
+// Match return true if short url match filter
+func (f ShortURLFilter) Match(model *ShortURLModel) bool {
+	return f.Tag == "" || slices.Contains(model.Tags, f.Tag)
+}
+
+// NormalizeTags return trimmed lower-case tags without duplicates
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTags {
+		return nil, ErrInvalidMetadata
+	}
+
+	result := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidMetadata
+		}
+
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+// ValidateMetadata check title and notes length
+func ValidateMetadata(title, notes string) error {
+	if utf8.RuneCountInString(title) > maxTitleLength || utf8.RuneCountInString(notes) > maxNotesLength {
+		return ErrInvalidMetadata
+	}
+
+	return nil
+}
@@ -2,186 +2,132 @@ package shorter
 
 import (
 	"context"
-	"math/rand/v2"
-	"net/url"
-	"strings"
+	"errors"
+	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
-	"github.com/InsideGallery/core/db/mongodb"
-	"github.com/InsideGallery/core/errors"
-	"github.com/InsideGallery/core/utils"
+	"github.com/InsideGallery/brf.im/rules"
 )
 
-var ErrPrefixToLong error = errors.New("prefix too long")
+var (
+	ErrPrefixToLong      error = errors.New("prefix too long")
+	ErrShortIDsExhausted error = errors.New("error short ids exhausted")
+)
 
 const (
 	CollectionOwner     = "owner"
 	CollectionShortURLs = "short_urls"
+	CollectionCounters  = "counters"
+	CollectionVersions  = "short_url_versions"
+	CollectionLimits    = "limits"
 
 	defaultRetries = 3
+	// maxRetries limit collisions on create, generators make ids longer every defaultRetries collisions,
+	// so only exhausted id space (or broken generator) reach it
+	maxRetries = 64
 )
 
 type OwnerModel struct {
-	ID primitive.ObjectID `bson:"_id" json:"owner"`
+	ID        primitive.ObjectID `bson:"_id" json:"owner"`
+	Generator *GeneratorConfig   `bson:"generator,omitempty" json:"generator,omitempty"`
 }
 
+// ShortURLModel describe short url, url (or weighted variant) is used when no routing rule, platform
+// or country override match visitor, empty status mean active link, clicks are maintained by store
+// and never changed by UpdateShortURL, password hash is never exposed by api
 type ShortURLModel struct {
-	ShortID string             `bson:"short_id" json:"shortID"`
-	Owner   primitive.ObjectID `bson:"owner" json:"owner"`
-	URL     string             `bson:"url" json:"url"`
+	ShortID         string             `bson:"short_id" json:"shortID"`
+	Owner           primitive.ObjectID `bson:"owner" json:"owner"`
+	URL             string             `bson:"url" json:"url"`
+	Rules           []rules.Rule       `bson:"rules,omitempty" json:"rules,omitempty"`
+	Platforms       map[string]string  `bson:"platforms,omitempty" json:"platforms,omitempty"`
+	Countries       map[string]string  `bson:"countries,omitempty" json:"countries,omitempty"`
+	Variants        []Variant          `bson:"variants,omitempty" json:"variants,omitempty"`
+	Sticky          bool               `bson:"sticky,omitempty" json:"sticky,omitempty"`
+	QueryPolicy     string             `bson:"query_policy,omitempty" json:"queryPolicy,omitempty"`
+	PathPassthrough bool               `bson:"path_passthrough,omitempty" json:"pathPassthrough,omitempty"`
+	RedirectMode    string             `bson:"redirect_mode,omitempty" json:"redirectMode,omitempty"`
+	Pixels          []Pixel            `bson:"pixels,omitempty" json:"pixels,omitempty"`
+	PixelDelay      int64              `bson:"pixel_delay,omitempty" json:"pixelDelay,omitempty"`
+	Title           string             `bson:"title,omitempty" json:"title,omitempty"`
+	Notes           string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	Tags            []string           `bson:"tags,omitempty" json:"tags,omitempty"`
+	ExpiresAt       *time.Time         `bson:"expires_at,omitempty" json:"expiresAt,omitempty"`
+	MaxClicks       int64              `bson:"max_clicks,omitempty" json:"maxClicks,omitempty"`
+	NotBefore       *time.Time         `bson:"not_before,omitempty" json:"notBefore,omitempty"`
+	NotAfter        *time.Time         `bson:"not_after,omitempty" json:"notAfter,omitempty"`
+	Timezone        string             `bson:"timezone,omitempty" json:"timezone,omitempty"`
+	Status          string             `bson:"status,omitempty" json:"status,omitempty"`
+	StatusMessage   string             `bson:"status_message,omitempty" json:"statusMessage,omitempty"`
+	PasswordHash    string             `bson:"password_hash,omitempty" json:"-"`
+	Clicks          int64              `bson:"clicks" json:"clicks"`
+	VariantClicks   map[string]int64   `bson:"variant_clicks,omitempty" json:"variantClicks,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updatedAt"`
 }
 
-func CreateOwner(ctx context.Context) (primitive.ObjectID, error) {
-	db, err := mongodb.Default()
-	if err != nil {
-		return primitive.ObjectID{}, err
-	}
-	id := primitive.NewObjectID()
-	err = db.InsertOne(ctx, CollectionOwner, &OwnerModel{
-		ID: id,
-	})
-
-	return id, err
+// IsExpired return true if short url is expired at given time
+func (m *ShortURLModel) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
 }
 
-func RemoveOwner(ctx context.Context, id primitive.ObjectID) error {
-	db, err := mongodb.Default()
-	if err != nil {
-		return err
-	}
-	filter := bson.D{{Key: "owner", Value: id}}
+// GetOwnerGenerator return generator configured for owner, or deployment default
+func GetOwnerGenerator(ctx context.Context, store Store, owner primitive.ObjectID) (ShortIDGenerator, error) {
+	cfg := defaultGeneratorConfig
 
-	_, err = db.Collection(CollectionShortURLs).DeleteMany(ctx, filter)
-	if err != nil {
-		return err
+	model, err := store.GetOwner(ctx, owner)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
 	}
-	filter = bson.D{{Key: "_id", Value: id}}
 
-	err = db.DeleteOne(ctx, CollectionOwner, filter)
-	if err != nil {
-		return err
+	if model != nil && model.Generator != nil {
+		cfg = model.Generator.Merge(defaultGeneratorConfig)
 	}
 
-	return err
+	return NewShortIDGenerator(cfg, store)
 }
 
-func CreateShortURL(ctx context.Context, prefix, url string, owner primitive.ObjectID) (string, error) {
-	db, err := mongodb.Default()
-	if err != nil {
-		return "", err
-	}
-
-	var shortID string
-	var retries int
-
-	for {
-		rawShortID, err := utils.GetTinyID()
-		if err != nil {
-			return "", err
+// CreateShortURL generate short id and store short url, ids which break character policy
+// or are reserved are handled as collisions
+func CreateShortURL(
+	ctx context.Context,
+	store Store,
+	generator ShortIDGenerator,
+	reserved *ReservedWords,
+	prefix string,
+	model *ShortURLModel,
+) (string, error) {
+	for retries := 0; ; retries++ {
+		if retries >= maxRetries {
+			return "", ErrShortIDsExhausted
 		}
 
-		if retries >= defaultRetries {
-			rawShortID = append(rawShortID, GetRandomChars(retries/defaultRetries)...) // every 3 retries add character
-		}
-
-		shortID = string(rawShortID)
-		if prefix != "" {
-			shortID = strings.Join([]string{prefix, shortID[2:]}, "-")
-		}
-
-		filter := bson.D{{Key: "short_id", Value: shortID}}
-
-		count, err := db.CountDocuments(ctx, CollectionShortURLs, filter)
+		shortID, err := generator.Generate(ctx, prefix, retries)
 		if err != nil {
 			return "", err
 		}
 
-		if count == 0 {
-			break
+		if reserved.Check(shortID) != nil {
+			continue
 		}
 
-		retries++
-	}
-	err = db.InsertOne(ctx, CollectionShortURLs, &ShortURLModel{
-		ShortID: shortID,
-		Owner:   owner,
-		URL:     url,
-	})
-
-	return shortID, err
-}
+		model.ShortID = shortID
 
-func RemoveShortURL(ctx context.Context, shortID string, owner primitive.ObjectID) error {
-	db, err := mongodb.Default()
-	if err != nil {
-		return err
-	}
-	filter := bson.D{{Key: "short_id", Value: shortID}, {Key: "owner", Value: owner}}
-
-	err = db.DeleteOne(ctx, CollectionShortURLs, filter)
-	if err != nil {
-		return err
-	}
-
-	return err
-}
-
-func GetShortURLs(ctx context.Context, owner primitive.ObjectID) ([]ShortURLModel, error) {
-	shortURLModel := new(ShortURLModel)
-
-	db, err := mongodb.Default()
-	if err != nil {
-		return nil, err
-	}
-	filter := bson.D{{Key: "owner", Value: owner}}
-	data, err := db.Find(ctx, CollectionShortURLs, shortURLModel, filter)
-	result := make([]ShortURLModel, len(data))
-
-	for i, a := range data {
-		result[i] = a.(ShortURLModel)
-		result[i].ShortID = url.PathEscape(result[i].ShortID)
-	}
-
-	return result, err
-}
-
-func GetShortURL(ctx context.Context, shortID string, owner primitive.ObjectID) (*ShortURLModel, error) {
-	shortURLModel := new(ShortURLModel)
-
-	db, err := mongodb.Default()
-	if err != nil {
-		return shortURLModel, err
+		err = store.InsertShortURL(ctx, model)
+		if !errors.Is(err, ErrShortIDExists) {
+			return shortID, err
+		}
 	}
-
-	filter := bson.D{{Key: "short_id", Value: shortID}, {Key: "owner", Value: owner}}
-	err = db.FindOne(ctx, CollectionShortURLs, shortURLModel, filter)
-	shortURLModel.ShortID = url.PathEscape(shortURLModel.ShortID)
-
-	return shortURLModel, err
 }
 
-func GetFullURL(ctx context.Context, shortID string) (string, error) {
-	shortURLModel := new(ShortURLModel)
+// CreateAliasShortURL claim exact short id, return ErrShortIDExists when alias already taken
+func CreateAliasShortURL(ctx context.Context, store Store, alias string, model *ShortURLModel) error {
+	model.ShortID = alias
 
-	db, err := mongodb.Default()
-	if err != nil {
-		return "", err
-	}
-	filter := bson.D{{Key: "short_id", Value: shortID}}
-	err = db.FindOne(ctx, CollectionShortURLs, shortURLModel, filter)
-
-	return shortURLModel.URL, err
+	return store.InsertShortURL(ctx, model)
 }
 
-var chars = []byte("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789")
-
 func GetRandomChars(n int) []byte {
-	result := make([]byte, n)
-	for i := 0; i < n; i++ {
-		result[i] = chars[rand.IntN(len(chars))] //nolint:gosec
-	}
-
-	return result
+	return getRandomCharsFrom(AlphabetDefault, n)
 }
@@ -0,0 +1,77 @@
+package shorter
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+// collidingGenerator return the same id for the same attempt, so parallel creates always collide
+type collidingGenerator struct{}
+
+func (collidingGenerator) Generate(_ context.Context, prefix string, attempt int) (string, error) {
+	return withPrefix(prefix, "id"+strconv.Itoa(attempt)), nil
+}
+
+func TestCreateShortURLParallel(t *testing.T) {
+	const workers = 32
+
+	for name, store := range testStores(t) {
+		random, err := NewShortIDGenerator(GeneratorConfig{}, store)
+		testutils.Equal(t, err, nil)
+
+		generators := map[string]ShortIDGenerator{
+			"random":    random,
+			"colliding": collidingGenerator{},
+		}
+
+		for generatorName, generator := range generators {
+			t.Run(name+"/"+generatorName, func(t *testing.T) {
+				owner := primitive.NewObjectID()
+				reserved := NewReservedWords()
+
+				var wg sync.WaitGroup
+
+				ids := make([]string, workers)
+				errs := make([]error, workers)
+
+				for i := range workers {
+					wg.Add(1)
+
+					go func() {
+						defer wg.Done()
+
+						model := &ShortURLModel{Owner: owner, URL: "https://example.com/" + strconv.Itoa(i)}
+						ids[i], errs[i] = CreateShortURL(context.Background(), store, generator, reserved, "", model)
+					}()
+				}
+
+				wg.Wait()
+
+				seen := map[string]struct{}{}
+
+				for i, id := range ids {
+					testutils.Equal(t, errs[i], nil)
+
+					_, duplicate := seen[id]
+					testutils.Equal(t, duplicate, false)
+
+					seen[id] = struct{}{}
+
+					model, err := store.GetShortURL(context.Background(), id, owner)
+					testutils.Equal(t, err, nil)
+					testutils.Equal(t, model.URL, "https://example.com/"+strconv.Itoa(i))
+				}
+
+				models, err := store.GetShortURLs(context.Background(), owner, ShortURLFilter{})
+				testutils.Equal(t, err, nil)
+				testutils.Equal(t, len(models), workers)
+			})
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/InsideGallery/core/db/mongodb"
+)
+
+// MongoStore store based on mongodb collections
+type MongoStore struct {
+	client *mongodb.MongoClient
+}
+
+// NewMongoStore return new mongo store
+func NewMongoStore(client *mongodb.MongoClient) *MongoStore {
+	return &MongoStore{client: client}
+}
+
+func (s *MongoStore) NextSequence(ctx context.Context, name string) (uint64, error) {
+	var counter struct {
+		Seq uint64 `bson:"seq"`
+	}
+
+	filter := bson.D{{Key: "_id", Value: name}}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "seq", Value: 1}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	err := s.client.Collection(CollectionCounters).FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
+
+	return counter.Seq, err
+}
+
+func (s *MongoStore) CreateOwner(ctx context.Context, model *OwnerModel) (primitive.ObjectID, error) {
+	model.ID = primitive.NewObjectID()
+	err := s.client.InsertOne(ctx, CollectionOwner, model)
+
+	return model.ID, err
+}
+
+func (s *MongoStore) GetOwner(ctx context.Context, id primitive.ObjectID) (*OwnerModel, error) {
+	ownerModel := new(OwnerModel)
+	err := s.client.FindOneByID(ctx, CollectionOwner, ownerModel, id)
+
+	return ownerModel, mapMongoError(err)
+}
+
+func (s *MongoStore) UpdateOwner(ctx context.Context, model *OwnerModel) error {
+	filter := bson.D{{Key: "_id", Value: model.ID}}
+
+	result, err := s.client.Collection(CollectionOwner).ReplaceOne(ctx, filter, model)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MongoStore) RemoveOwner(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.D{{Key: "owner", Value: id}}
+
+	_, err := s.client.Collection(CollectionShortURLs).DeleteMany(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Collection(CollectionVersions).DeleteMany(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	filter = bson.D{{Key: "_id", Value: id}}
+
+	return s.client.DeleteOne(ctx, CollectionOwner, filter)
+}
+
+// EnsureIndexes create indexes required by store, must be called on startup
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.client.Collection(CollectionShortURLs).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "short_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "owner", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "owner", Value: 1}, {Key: "tags", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Collection(CollectionVersions).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "short_id", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "owner", Value: 1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Collection(CollectionLimits).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
+}
+
+func (s *MongoStore) InsertShortURL(ctx context.Context, model *ShortURLModel) error {
+	err := s.client.InsertOne(ctx, CollectionShortURLs, model)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrShortIDExists
+	}
+
+	return err
+}
+
+func (s *MongoStore) RemoveShortURL(ctx context.Context, shortID string, owner primitive.ObjectID) error {
+	filter := bson.D{{Key: "short_id", Value: shortID}, {Key: "owner", Value: owner}}
+
+	err := s.client.DeleteOne(ctx, CollectionShortURLs, filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Collection(CollectionVersions).DeleteMany(ctx, filter)
+
+	return err
+}
+
+func (s *MongoStore) GetShortURLs(
+	ctx context.Context,
+	owner primitive.ObjectID,
+	shortURLFilter ShortURLFilter,
+) ([]ShortURLModel, error) {
+	filter := bson.D{{Key: "owner", Value: owner}}
+	if shortURLFilter.Tag != "" {
+		filter = append(filter, bson.E{Key: "tags", Value: shortURLFilter.Tag})
+	}
+
+	data, err := s.client.Find(ctx, CollectionShortURLs, new(ShortURLModel), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ShortURLModel, len(data))
+	for i, a := range data {
+		result[i] = a.(ShortURLModel)
+	}
+
+	return result, nil
+}
+
+func (s *MongoStore) GetShortURL(
+	ctx context.Context,
+	shortID string,
+	owner primitive.ObjectID,
+) (*ShortURLModel, error) {
+	shortURLModel := new(ShortURLModel)
+
+	filter := bson.D{{Key: "short_id", Value: shortID}, {Key: "owner", Value: owner}}
+	err := s.client.FindOne(ctx, CollectionShortURLs, shortURLModel, filter)
+
+	return shortURLModel, mapMongoError(err)
+}
+
+func (s *MongoStore) ResolveShortURL(ctx context.Context, shortID string) (*ShortURLModel, error) {
+	shortURLModel := new(ShortURLModel)
+
+	filter := bson.D{{Key: "short_id", Value: shortID}}
+	err := s.client.FindOne(ctx, CollectionShortURLs, shortURLModel, filter)
+
+	return shortURLModel, mapMongoError(err)
+}
+
+func (s *MongoStore) UpdateShortURL(ctx context.Context, model *ShortURLModel) error {
+	filter := bson.D{{Key: "short_id", Value: model.ShortID}, {Key: "owner", Value: model.Owner}}
+
+	// status is omitted from replacement, so missing status of active link is not overwritten
+	replacement := *model
+	replacement.Status = ""
+	replacement.StatusMessage = ""
+
+	// replace document, but keep fields maintained by store
+	update := mongo.Pipeline{{{Key: "$replaceWith", Value: bson.D{{Key: "$mergeObjects", Value: bson.A{
+		bson.D{{Key: "$literal", Value: replacement}},
+		bson.D{
+			{Key: "_id", Value: "$_id"},
+			{Key: "clicks", Value: "$clicks"},
+			{Key: "variant_clicks", Value: "$variant_clicks"},
+			{Key: "status", Value: "$status"},
+			{Key: "status_message", Value: "$status_message"},
+		},
+	}}}}}}
+
+	result, err := s.client.Collection(CollectionShortURLs).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MongoStore) UpdateShortURLStatus(
+	ctx context.Context,
+	model *ShortURLModel,
+	admin bool,
+) (*ShortURLModel, error) {
+	filter := bson.D{{Key: "short_id", Value: model.ShortID}}
+	if !admin {
+		filter = append(filter,
+			bson.E{Key: "owner", Value: model.Owner},
+			bson.E{Key: "status", Value: bson.D{{Key: "$ne", Value: StatusBlocked}}},
+		)
+	}
+
+	set := bson.D{{Key: "updated_at", Value: model.UpdatedAt}}
+	unset := bson.D{}
+
+	for _, field := range []bson.E{
+		{Key: "status", Value: model.Status},
+		{Key: "status_message", Value: model.StatusMessage},
+	} {
+		if field.Value == "" {
+			unset = append(unset, bson.E{Key: field.Key, Value: ""})
+		} else {
+			set = append(set, field)
+		}
+	}
+
+	update := bson.D{{Key: "$set", Value: set}}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+
+	updated := new(ShortURLModel)
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err := s.client.Collection(CollectionShortURLs).FindOneAndUpdate(ctx, filter, update, opts).Decode(updated)
+	if !admin && errors.Is(err, mongo.ErrNoDocuments) {
+		// owner filter does not match blocked link, so it is checked whether link exists
+		_, err := s.GetShortURL(ctx, model.ShortID, model.Owner)
+		if err == nil {
+			return new(ShortURLModel), ErrBlocked
+		}
+
+		return new(ShortURLModel), err
+	}
+
+	return updated, mapMongoError(err)
+}
+
+func (s *MongoStore) PurgeExpired(ctx context.Context, before time.Time) ([]string, error) {
+	filter := bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: before}}}}
+
+	var shortIDs []string
+
+	data, err := s.client.Find(ctx, CollectionShortURLs, new(ShortURLModel), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range data {
+		shortIDs = append(shortIDs, a.(ShortURLModel).ShortID)
+	}
+
+	if len(shortIDs) == 0 {
+		return nil, nil
+	}
+
+	// expiration could be extended after find, so it is checked again on delete
+	filter = bson.D{
+		{Key: "short_id", Value: bson.D{{Key: "$in", Value: shortIDs}}},
+		{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: before}}},
+	}
+
+	err = s.client.DeleteMany(ctx, CollectionShortURLs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	shortIDs, err = s.purgedShortIDs(ctx, shortIDs)
+	if err != nil || len(shortIDs) == 0 {
+		return nil, err
+	}
+
+	filter = bson.D{{Key: "short_id", Value: bson.D{{Key: "$in", Value: shortIDs}}}}
+
+	return shortIDs, s.client.DeleteMany(ctx, CollectionVersions, filter)
+}
+
+// purgedShortIDs return short ids, which are not stored anymore
+func (s *MongoStore) purgedShortIDs(ctx context.Context, shortIDs []string) ([]string, error) {
+	filter := bson.D{{Key: "short_id", Value: bson.D{{Key: "$in", Value: shortIDs}}}}
+
+	data, err := s.client.Find(ctx, CollectionShortURLs, new(ShortURLModel), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make(map[string]struct{}, len(data))
+	for _, a := range data {
+		kept[a.(ShortURLModel).ShortID] = struct{}{}
+	}
+
+	purged := make([]string, 0, len(shortIDs))
+
+	for _, shortID := range shortIDs {
+		if _, ok := kept[shortID]; !ok {
+			purged = append(purged, shortID)
+		}
+	}
+
+	return purged, nil
+}
+
+func (s *MongoStore) ShortIDs(ctx context.Context, fn func(shortID string) error) error {
+	opts := options.Find().SetProjection(bson.D{{Key: "short_id", Value: 1}})
+
+	cur, err := s.client.Collection(CollectionShortURLs).Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := cur.Close(ctx); err != nil {
+			slog.Error("Error closing cursor", "err", err)
+		}
+	}()
+
+	for cur.Next(ctx) {
+		var model ShortURLModel
+
+		err = cur.Decode(&model)
+		if err != nil {
+			return err
+		}
+
+		err = fn(model.ShortID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+func (s *MongoStore) IncrementClicks(ctx context.Context, shortID, variant string) (bool, error) {
+	// limit is checked by filter, so concurrent clicks could not pass it together
+	filter := bson.D{
+		{Key: "short_id", Value: shortID},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "max_clicks", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{
+				bson.D{{Key: "$ifNull", Value: bson.A{"$clicks", 0}}},
+				"$max_clicks",
+			}}}}},
+		}},
+	}
+	inc := bson.D{{Key: "clicks", Value: 1}}
+	if variant != "" {
+		// variant names contain only letters, digits, `-` and `_`, so they are safe field names
+		inc = append(inc, bson.E{Key: "variant_clicks." + variant, Value: 1})
+	}
+
+	update := bson.D{{Key: "$inc", Value: inc}}
+
+	res, err := s.client.Collection(CollectionShortURLs).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return res.MatchedCount > 0, nil
+}
+
+func (s *MongoStore) AddVersion(ctx context.Context, model *VersionModel) error {
+	return s.client.InsertOne(ctx, CollectionVersions, model)
+}
+
+func (s *MongoStore) GetVersions(ctx context.Context, shortID string) ([]VersionModel, error) {
+	filter := bson.D{{Key: "short_id", Value: shortID}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cur, err := s.client.Collection(CollectionVersions).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []VersionModel
+
+	err = cur.All(ctx, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *MongoStore) GetVersion(ctx context.Context, shortID string, id primitive.ObjectID) (*VersionModel, error) {
+	model := new(VersionModel)
+
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "short_id", Value: shortID}}
+	err := s.client.FindOne(ctx, CollectionVersions, model, filter)
+
+	return model, mapMongoError(err)
+}
+
+func mapMongoError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,151 @@
+package shorter
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidPassword error = errors.New("error invalid password")
+
+const (
+	UnlockCookie = "brfim_unlock"
+
+	minPasswordLength = 4
+	maxPasswordLength = 72 // bcrypt ignore everything after 72 bytes
+	defaultUnlockTTL  = 15 * time.Minute
+	unlockSecretSize  = 32
+
+	defaultUnlockMaxAttempts = 5
+	defaultUnlockWindow      = 15 * time.Minute
+)
+
+// HashPassword return bcrypt hash of link password
+func HashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return "", ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// IsProtected return true if short url require password
+func (m *ShortURLModel) IsProtected() bool {
+	return m.PasswordHash != ""
+}
+
+// CheckPassword return true if password match short url password
+func (m *ShortURLModel) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(m.PasswordHash), []byte(password)) == nil
+}
+
+// UnlockConfig describe signing of unlock cookies and limit of wrong password attempts
+type UnlockConfig struct {
+	Secret      []byte
+	TTL         time.Duration
+	MaxAttempts int
+	Window      time.Duration
+}
+
+// GetUnlockConfigFromEnv return unlock config from UNLOCK_SECRET, UNLOCK_TTL, UNLOCK_MAX_ATTEMPTS
+// and UNLOCK_ATTEMPTS_WINDOW, without secret random one is used, so cookies are valid only for this instance
+func GetUnlockConfigFromEnv() UnlockConfig {
+	cfg := UnlockConfig{
+		Secret:      []byte(os.Getenv("UNLOCK_SECRET")),
+		TTL:         defaultUnlockTTL,
+		MaxAttempts: defaultUnlockMaxAttempts,
+		Window:      defaultUnlockWindow,
+	}
+
+	ttl, err := time.ParseDuration(os.Getenv("UNLOCK_TTL"))
+	if err == nil && ttl > 0 {
+		cfg.TTL = ttl
+	}
+
+	maxAttempts, err := strconv.Atoi(os.Getenv("UNLOCK_MAX_ATTEMPTS"))
+	if err == nil && maxAttempts > 0 {
+		cfg.MaxAttempts = maxAttempts
+	}
+
+	window, err := time.ParseDuration(os.Getenv("UNLOCK_ATTEMPTS_WINDOW"))
+	if err == nil && window > 0 {
+		cfg.Window = window
+	}
+
+	if len(cfg.Secret) == 0 {
+		slog.Warn("UNLOCK_SECRET is not set, random secret is used, so unlock cookies are valid " +
+			"only for this instance until restart, set shared secret when more than one instance is running")
+
+		cfg.Secret = make([]byte, unlockSecretSize)
+		_, _ = rand.Read(cfg.Secret)
+	}
+
+	return cfg
+}
+
+// Unlocker issue and verify signed cookies for unlocked password-protected links
+type Unlocker struct {
+	secret      []byte
+	ttl         time.Duration
+	maxAttempts int
+	window      time.Duration
+}
+
+// NewUnlocker return new unlocker
+func NewUnlocker(cfg UnlockConfig) *Unlocker {
+	return &Unlocker{
+		secret:      cfg.Secret,
+		ttl:         cfg.TTL,
+		maxAttempts: cfg.MaxAttempts,
+		window:      cfg.Window,
+	}
+}
+
+// Sign return cookie value which unlock short url until returned time,
+// value is bound to password hash, so changing password revoke issued cookies
+func (u *Unlocker) Sign(model *ShortURLModel, now time.Time) (string, time.Time) {
+	expiresAt := now.Add(u.ttl)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return expires + "." + u.signature(model, expires), expiresAt
+}
+
+// Verify return true if cookie value unlock short url at given time
+func (u *Unlocker) Verify(model *ShortURLModel, value string, now time.Time) bool {
+	expires, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !now.Before(time.Unix(unix, 0)) {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(u.signature(model, expires)))
+}
+
+func (u *Unlocker) signature(model *ShortURLModel, expires string) string {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(model.ShortID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(model.PasswordHash))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,70 @@
+package shorter
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidPath error = errors.New("error invalid path")
+
+// JoinPath append raw (escaped) path to path of destination. Every segment is unescaped and escaped again,
+// so encoded separators could not change path structure, dot segments, empty segments (except trailing one),
+// backslashes and control characters are rejected
+func JoinPath(destination *url.URL, rest string) error {
+	if rest == "" {
+		return nil
+	}
+
+	rawSegments := strings.Split(rest, "/")
+	segments := make([]string, 0, len(rawSegments))
+	escaped := make([]string, 0, len(rawSegments))
+
+	for i, raw := range rawSegments {
+		segment, err := url.PathUnescape(raw)
+		if err != nil {
+			return ErrInvalidPath
+		}
+
+		if segment == "" && i != len(rawSegments)-1 {
+			return ErrInvalidPath
+		}
+
+		if segment == "." || segment == ".." || strings.ContainsAny(segment, "/\\") || hasControl(segment) {
+			return ErrInvalidPath
+		}
+
+		segments = append(segments, segment)
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+
+	base := strings.TrimSuffix(destination.Path, "/")
+	rawBase := strings.TrimSuffix(destination.EscapedPath(), "/")
+
+	destination.Path = base + "/" + strings.Join(segments, "/")
+	destination.RawPath = rawBase + "/" + strings.Join(escaped, "/")
+
+	return nil
+}
+
+// GetRestPath return raw path after /<shortID>/, trailing slash is kept
+func GetRestPath(c *fiber.Ctx) string {
+	rest, found := strings.CutPrefix(string(c.Request().URI().PathOriginal()), "/"+c.Params("shortID")+"/")
+	if !found {
+		return ""
+	}
+
+	return rest
+}
+
+func hasControl(s string) bool {
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package shorter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestJoinPath(t *testing.T) {
+	cases := []struct {
+		name        string
+		destination string
+		rest        string
+		want        string
+		err         error
+	}{
+		{name: "empty rest", destination: "https://example.com/base?q=1#top", want: "https://example.com/base?q=1#top"},
+		{name: "segments", destination: "https://example.com/base", rest: "a/b", want: "https://example.com/base/a/b"},
+		{name: "destination without path", destination: "https://example.com", rest: "a", want: "https://example.com/a"},
+		{name: "destination with trailing slash", destination: "https://example.com/base/", rest: "a", want: "https://example.com/base/a"},
+		{
+			name:        "destination with query and fragment",
+			destination: "https://example.com/base?q=1&r=2#top",
+			rest:        "a/b",
+			want:        "https://example.com/base/a/b?q=1&r=2#top",
+		},
+		{
+			name:        "destination with escaped path",
+			destination: "https://example.com/a%2Fb",
+			rest:        "c",
+			want:        "https://example.com/a%2Fb/c",
+		},
+		{name: "trailing slash", destination: "https://example.com/base", rest: "a/", want: "https://example.com/base/a/"},
+		{name: "encoded space", destination: "https://example.com", rest: "a%20b", want: "https://example.com/a%20b"},
+		{name: "raw space", destination: "https://example.com", rest: "a b", want: "https://example.com/a%20b"},
+		{name: "encoded question mark and hash", destination: "https://example.com?q=1", rest: "a%3Fb%23c", want: "https://example.com/a%3Fb%23c?q=1"},
+		{name: "encoded unicode", destination: "https://example.com", rest: "caf%C3%A9", want: "https://example.com/caf%C3%A9"},
+		{name: "dot inside segment", destination: "https://example.com", rest: "v1.2/file.txt", want: "https://example.com/v1.2/file.txt"},
+		{name: "dot dot", destination: "https://example.com/base", rest: "..", err: ErrInvalidPath},
+		{name: "dot dot after segment", destination: "https://example.com/base", rest: "a/../../admin", err: ErrInvalidPath},
+		{name: "encoded dot dot", destination: "https://example.com/base", rest: "%2e%2e/admin", err: ErrInvalidPath},
+		{name: "encoded upper case dot dot", destination: "https://example.com/base", rest: "a/%2E%2E", err: ErrInvalidPath},
+		{name: "dot", destination: "https://example.com/base", rest: "./a", err: ErrInvalidPath},
+		{name: "encoded slash", destination: "https://example.com/base", rest: "a%2Fb", err: ErrInvalidPath},
+		{name: "encoded slash with dot dot", destination: "https://example.com/base", rest: "..%2Fadmin", err: ErrInvalidPath},
+		{name: "backslash", destination: "https://example.com/base", rest: `a\b`, err: ErrInvalidPath},
+		{name: "encoded backslash", destination: "https://example.com/base", rest: "..%5Cadmin", err: ErrInvalidPath},
+		{name: "double slash", destination: "https://example.com/base", rest: "a//b", err: ErrInvalidPath},
+		{name: "leading slash", destination: "https://example.com/base", rest: "/evil.com", err: ErrInvalidPath},
+		{name: "only slash", destination: "https://example.com/base", rest: "/", err: ErrInvalidPath},
+		{name: "null byte", destination: "https://example.com/base", rest: "a%00b", err: ErrInvalidPath},
+		{name: "new line", destination: "https://example.com/base", rest: "a%0D%0ALocation:%20x", err: ErrInvalidPath},
+		{name: "delete character", destination: "https://example.com/base", rest: "a%7F", err: ErrInvalidPath},
+		{name: "invalid escape", destination: "https://example.com/base", rest: "a%zz", err: ErrInvalidPath},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			destination, err := url.Parse(tc.destination)
+			testutils.Equal(t, err, nil)
+
+			err = JoinPath(destination, tc.rest)
+			testutils.Equal(t, err, tc.err)
+
+			if tc.err == nil {
+				testutils.Equal(t, destination.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestGetRestPath(t *testing.T) {
+	app := fiber.New()
+	app.Get("/:shortID", func(c *fiber.Ctx) error {
+		return c.SendString(GetRestPath(c))
+	})
+	app.Get("/:shortID/*", func(c *fiber.Ctx) error {
+		return c.SendString(GetRestPath(c))
+	})
+
+	cases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "link", path: "/abc", want: ""},
+		{name: "segments", path: "/abc/a/b", want: "a/b"},
+		{name: "trailing slash", path: "/abc/a/", want: "a/"},
+		{name: "only slash", path: "/abc/", want: ""},
+		{name: "double slash", path: "/abc//evil.com", want: "/evil.com"},
+		{name: "encoded characters are kept", path: "/abc/a%2Fb/%2e%2e/%20", want: "a%2Fb/%2e%2e/%20"},
+		{name: "query is not included", path: "/abc/a?b=c", want: "a"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tc.path, nil), -1)
+			testutils.Equal(t, err, nil)
+
+			body, err := io.ReadAll(resp.Body)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, string(body), tc.want)
+		})
+	}
+}
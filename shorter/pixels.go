@@ -0,0 +1,140 @@
+package shorter
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/InsideGallery/brf.im/handler/pages"
+	"github.com/InsideGallery/core/server/template"
+)
+
+var ErrInvalidPixels error = errors.New("error invalid pixels")
+
+const (
+	PixelFacebook = "facebook"
+	PixelGoogle   = "google"
+	PixelLinkedIn = "linkedin"
+	// PixelImage load image from https url
+	PixelImage = "image"
+
+	maxPixels          = 10
+	maxPixelIDLength   = 32
+	maxPixelURLLength  = 2048
+	maxPixelDelay      = 10 * time.Second
+	defaultPixelDelay  = time.Second
+	pixelDelayFraction = 999 * time.Millisecond
+)
+
+var deploymentPixelDelay = GetPixelDelayFromEnv()
+
+// Pixel describe retargeting pixel loaded by interstitial page, providers are configured by ID,
+// image by URL, so owners could not inject markup or own scripts into page on origin of short links
+type Pixel struct {
+	Type string `bson:"type" json:"type"`
+	ID   string `bson:"id,omitempty" json:"id,omitempty"`
+	URL  string `bson:"url,omitempty" json:"url,omitempty"`
+}
+
+// GetPixelDelayFromEnv return deployment default delay of interstitial page from PIXEL_DELAY
+func GetPixelDelayFromEnv() time.Duration {
+	delay, err := time.ParseDuration(os.Getenv("PIXEL_DELAY"))
+	if err != nil || delay < 0 || delay > maxPixelDelay {
+		return defaultPixelDelay
+	}
+
+	return delay
+}
+
+// ValidatePixels check pixels have known type and valid id or https url, delay is in milliseconds
+func ValidatePixels(pixels []Pixel, delay int64) error {
+	if len(pixels) > maxPixels || delay < 0 || delay > maxPixelDelay.Milliseconds() {
+		return ErrInvalidPixels
+	}
+
+	for _, pixel := range pixels {
+		switch pixel.Type {
+		case PixelFacebook, PixelGoogle, PixelLinkedIn:
+			if !isPixelID(pixel.ID) || pixel.URL != "" {
+				return ErrInvalidPixels
+			}
+		case PixelImage:
+			u, err := url.Parse(pixel.URL)
+			if err != nil || u.Scheme != "https" || u.Host == "" || len(pixel.URL) > maxPixelURLLength || pixel.ID != "" {
+				return ErrInvalidPixels
+			}
+		default:
+			return ErrInvalidPixels
+		}
+	}
+
+	return nil
+}
+
+// GetPixelDelay return delay of interstitial page of short url or deployment default
+func (m *ShortURLModel) GetPixelDelay() time.Duration {
+	if m.PixelDelay == 0 {
+		return deploymentPixelDelay
+	}
+
+	return time.Duration(m.PixelDelay) * time.Millisecond
+}
+
+// ShouldRenderPixels return true if visitor must see interstitial page with pixels of short url: link has pixels,
+// destination is third-party and visitor does not opt out from tracking by DNT or Sec-GPC headers
+func ShouldRenderPixels(c *fiber.Ctx, shortURL *ShortURLModel, destination *url.URL) bool {
+	if len(shortURL.Pixels) == 0 || c.Get("DNT") == "1" || c.Get("Sec-GPC") == "1" {
+		return false
+	}
+
+	return isThirdParty(destination)
+}
+
+func renderPixels(c *fiber.Ctx, tmpl *template.Engine, shortURL *ShortURLModel, location string) error {
+	if !isHTTPLocation(location) {
+		return refuseLocation(c, location)
+	}
+
+	delay := shortURL.GetPixelDelay()
+
+	pg := pages.NewPage("Redirecting | Brief I am", ``, ``, ``, ``, ``, `Redirecting to`)
+	pg.Add("url", location)
+	pg.Add("pixels", shortURL.Pixels)
+	pg.Add("delay", delay.Milliseconds())
+	// meta refresh is fallback for visitors without javascript, it support only whole seconds
+	pg.Add("seconds", strconv.FormatInt(int64((delay+pixelDelayFraction)/time.Second), 10))
+
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	return pages.Render(c, tmpl, pages.PixelTemplate, http.StatusOK, pg)
+}
+
+// isThirdParty return true if destination is not on host of short links
+func isThirdParty(destination *url.URL) bool {
+	link, err := url.Parse(urlLink)
+	if err != nil {
+		return true
+	}
+
+	return !strings.EqualFold(destination.Hostname(), link.Hostname())
+}
+
+func isPixelID(id string) bool {
+	if id == "" || len(id) > maxPixelIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if !isAlphanumeric(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
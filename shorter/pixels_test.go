@@ -0,0 +1,44 @@
+package shorter
+
+import (
+	"testing"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestValidatePixels(t *testing.T) {
+	cases := []struct {
+		name   string
+		pixels []Pixel
+		delay  int64
+		valid  bool
+	}{
+		{name: "no pixels", valid: true},
+		{
+			name: "providers and image",
+			pixels: []Pixel{
+				{Type: PixelFacebook, ID: "123"},
+				{Type: PixelGoogle, ID: "G-ABC_1"},
+				{Type: PixelLinkedIn, ID: "456"},
+				{Type: PixelImage, URL: "https://tracker.example.com/pixel.gif?id=1"},
+			},
+			delay: 2000,
+			valid: true,
+		},
+		{name: "script", pixels: []Pixel{{Type: "script", URL: "https://example.com/pixel.js"}}},
+		{name: "unknown type", pixels: []Pixel{{Type: "tiktok", ID: "123"}}},
+		{name: "provider id with markup", pixels: []Pixel{{Type: PixelFacebook, ID: "1');alert(1);//"}}},
+		{name: "provider with url", pixels: []Pixel{{Type: PixelGoogle, ID: "G-1", URL: "https://example.com"}}},
+		{name: "image over http", pixels: []Pixel{{Type: PixelImage, URL: "http://example.com/pixel.gif"}}},
+		{name: "image with javascript url", pixels: []Pixel{{Type: PixelImage, URL: "javascript:alert(1)"}}},
+		{name: "image with id", pixels: []Pixel{{Type: PixelImage, ID: "1", URL: "https://example.com/p.gif"}}},
+		{name: "negative delay", delay: -1},
+		{name: "too long delay", delay: maxPixelDelay.Milliseconds() + 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.Equal(t, ValidatePixels(tc.pixels, tc.delay) == nil, tc.valid)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package shorter
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/InsideGallery/brf.im/handler/pages"
+	"github.com/InsideGallery/core/server/template"
+)
+
+// PreviewSuffix suffix of short id which open preview instead of redirect (/<shortID>+)
+const PreviewSuffix = "+"
+
+// PreviewShortURLHandler render page with destination and details of short url, preview is not counted as click.
+// Short url is read bypassing redirect cache, which does not see clicks and could be stale
+func PreviewShortURLHandler(store Store, tmpl *template.Engine) fiber.Handler {
+	store = Uncached(store)
+
+	return func(c *fiber.Ctx) error {
+		shortID := strings.TrimSuffix(c.Params("shortID"), PreviewSuffix)
+
+		shortURL, err := store.ResolveShortURL(c.Context(), shortID)
+		if errors.Is(err, ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			_, err := c.WriteString("Error short url not found")
+
+			return err
+		}
+
+		if err != nil {
+			slog.Error("Error getting short url", "err", err, "shortID", shortID)
+
+			c.Status(http.StatusInternalServerError)
+			_, err := c.WriteString("Error getting short url")
+
+			return err
+		}
+
+		now := time.Now()
+
+		if shortURL.IsExpired(now) {
+			return renderExpired(c, tmpl)
+		}
+
+		// blocked links could be harmful, so their destination is never shown
+		if shortURL.GetStatus() == StatusBlocked {
+			return renderBlocked(c, tmpl, shortURL)
+		}
+
+		pg := pages.NewPage(
+			"Link preview | Brief I am", ``, ``, ``, ``,
+			`Link preview`,
+			`Check where this short link goes before opening it.`,
+		)
+		pg.Add("link", strings.Join([]string{urlLink, "/", url.PathEscape(shortURL.ShortID)}, ""))
+		pg.Add("open", "/"+url.PathEscape(shortURL.ShortID))
+		pg.Add("qr", "/qr/"+url.PathEscape(shortURL.ShortID))
+		pg.Add("title", shortURL.Title)
+		pg.Add("created", shortURL.CreatedAt.In(shortURL.Location()).Format(scheduleDisplayLayout))
+		pg.Add("clicks", strconv.FormatInt(shortURL.Clicks, 10))
+		pg.Add("state", previewState(shortURL, now))
+		pg.Add("protected", shortURL.IsProtected())
+		pg.Add("destinations", previewDestinations(shortURL))
+		pg.Add("varies", len(shortURL.Rules) > 0 || len(shortURL.Platforms) > 0 || len(shortURL.Countries) > 0)
+
+		return pages.Render(c, tmpl, pages.PreviewTemplate, http.StatusOK, pg)
+	}
+}
+
+// PreviewBySuffix render preview when short id end with PreviewSuffix, otherwise pass request to next handler
+func PreviewBySuffix(preview fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if strings.HasSuffix(c.Params("shortID"), PreviewSuffix) {
+			return preview(c)
+		}
+
+		return c.Next()
+	}
+}
+
+func previewState(shortURL *ShortURLModel, now time.Time) string {
+	if shortURL.GetStatus() == StatusPaused {
+		return StatusPaused
+	}
+
+	return shortURL.State(now)
+}
+
+// previewDestinations return main destinations of short url, destination of protected link is hidden
+func previewDestinations(shortURL *ShortURLModel) []string {
+	if shortURL.IsProtected() {
+		return nil
+	}
+
+	if len(shortURL.Variants) == 0 {
+		return []string{shortURL.URL}
+	}
+
+	destinations := make([]string, 0, len(shortURL.Variants))
+	for _, variant := range shortURL.Variants {
+		destinations = append(destinations, variant.URL)
+	}
+
+	return destinations
+}
@@ -0,0 +1,99 @@
+package shorter
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var ErrInvalidQueryPolicy error = errors.New("error invalid query policy")
+
+const (
+	// QueryAppend append incoming query to query of link, empty policy mean append
+	QueryAppend = "append"
+	// QueryMergeLink merge queries, values of link win for keys present in both
+	QueryMergeLink = "merge_link"
+	// QueryMergeIncoming merge queries, incoming values win for keys present in both
+	QueryMergeIncoming = "merge_incoming"
+	// QueryDrop ignore incoming query
+	QueryDrop = "drop"
+)
+
+// ValidateQueryPolicy check query policy is known
+func ValidateQueryPolicy(policy string) error {
+	switch policy {
+	case "", QueryAppend, QueryMergeLink, QueryMergeIncoming, QueryDrop:
+		return nil
+	}
+
+	return ErrInvalidQueryPolicy
+}
+
+// MergeQuery return raw query of destination built from raw query of link and incoming raw query by policy.
+// Parameters keep their order and encoding, every value of repeated key is taken from the winning side
+func MergeQuery(link, incoming, policy string) string {
+	switch policy {
+	case QueryDrop:
+		return link
+	case QueryMergeLink:
+		return joinQuery(link, filterQuery(incoming, queryKeys(link)))
+	case QueryMergeIncoming:
+		return joinQuery(filterQuery(link, queryKeys(incoming)), filterQuery(incoming, nil))
+	}
+
+	return joinQuery(link, filterQuery(incoming, nil))
+}
+
+func joinQuery(first, second string) string {
+	switch {
+	case first == "":
+		return second
+	case second == "":
+		return first
+	}
+
+	return first + "&" + second
+}
+
+// filterQuery return raw query without parameters with given keys and without empty parameters
+func filterQuery(query string, keys map[string]struct{}) string {
+	params := make([]string, 0, strings.Count(query, "&")+1)
+
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+
+		if _, ok := keys[queryKey(param)]; ok {
+			continue
+		}
+
+		params = append(params, param)
+	}
+
+	return strings.Join(params, "&")
+}
+
+func queryKeys(query string) map[string]struct{} {
+	keys := map[string]struct{}{}
+
+	for _, param := range strings.Split(query, "&") {
+		if param != "" {
+			keys[queryKey(param)] = struct{}{}
+		}
+	}
+
+	return keys
+}
+
+// queryKey return decoded key of raw parameter, so `a%5Bb%5D` and `a[b]` are the same key
+func queryKey(param string) string {
+	key, _, _ := strings.Cut(param, "=")
+
+	decoded, err := url.QueryUnescape(key)
+	if err != nil {
+		return key
+	}
+
+	return decoded
+}
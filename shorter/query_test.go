@@ -0,0 +1,87 @@
+package shorter
+
+import (
+	"testing"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestMergeQuery(t *testing.T) {
+	cases := []struct {
+		name     string
+		link     string
+		incoming string
+		policy   string
+		want     string
+	}{
+		{name: "append", link: "a=1&b=2", incoming: "b=3&c=4", policy: QueryAppend, want: "a=1&b=2&b=3&c=4"},
+		{name: "empty policy append", link: "a=1", incoming: "a=2", want: "a=1&a=2"},
+		{name: "append skip empty parameters", link: "a=1", incoming: "&b=2&", policy: QueryAppend, want: "a=1&b=2"},
+		{name: "append empty link", incoming: "c=4", policy: QueryAppend, want: "c=4"},
+		{name: "append empty incoming", link: "a=1", policy: QueryAppend, want: "a=1"},
+		{name: "append encoded values", link: "q=a%26b", incoming: "r=%23x", policy: QueryAppend, want: "q=a%26b&r=%23x"},
+		{
+			name:     "merge link",
+			link:     "a=1&b=2",
+			incoming: "b=3&c=4",
+			policy:   QueryMergeLink,
+			want:     "a=1&b=2&c=4",
+		},
+		{
+			name:     "merge link repeated keys",
+			link:     "tag=x&tag=y",
+			incoming: "tag=z&tag=w&page=2",
+			policy:   QueryMergeLink,
+			want:     "tag=x&tag=y&page=2",
+		},
+		{
+			name:     "merge link encoded keys",
+			link:     "a%5Bb%5D=1",
+			incoming: "a[b]=2&c=%23",
+			policy:   QueryMergeLink,
+			want:     "a%5Bb%5D=1&c=%23",
+		},
+		{name: "merge link empty link", incoming: "b=3&&c=4", policy: QueryMergeLink, want: "b=3&c=4"},
+		{name: "merge link empty incoming", link: "a=1", policy: QueryMergeLink, want: "a=1"},
+		{
+			name:     "merge incoming",
+			link:     "a=1&b=2",
+			incoming: "b=3&c=4",
+			policy:   QueryMergeIncoming,
+			want:     "a=1&b=3&c=4",
+		},
+		{
+			name:     "merge incoming repeated keys",
+			link:     "tag=x&tag=y&page=1",
+			incoming: "tag=z",
+			policy:   QueryMergeIncoming,
+			want:     "page=1&tag=z",
+		},
+		{
+			name:     "merge incoming encoded ampersand and hash",
+			link:     "q=a%26b&r=1",
+			incoming: "q=c%26d%23e",
+			policy:   QueryMergeIncoming,
+			want:     "r=1&q=c%26d%23e",
+		},
+		{name: "merge incoming empty link", incoming: "b=3&&c=4", policy: QueryMergeIncoming, want: "b=3&c=4"},
+		{name: "merge incoming empty incoming", link: "a=1", policy: QueryMergeIncoming, want: "a=1"},
+		{name: "drop", link: "a=1", incoming: "a=2&b=%23", policy: QueryDrop, want: "a=1"},
+		{name: "drop empty link", incoming: "a=2", policy: QueryDrop, want: ""},
+		{name: "both empty", policy: QueryMergeIncoming, want: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.Equal(t, MergeQuery(tc.link, tc.incoming, tc.policy), tc.want)
+		})
+	}
+}
+
+func TestValidateQueryPolicy(t *testing.T) {
+	for _, policy := range []string{"", QueryAppend, QueryMergeLink, QueryMergeIncoming, QueryDrop} {
+		testutils.Equal(t, ValidateQueryPolicy(policy), nil)
+	}
+
+	testutils.Equal(t, ValidateQueryPolicy("replace"), ErrInvalidQueryPolicy)
+}
@@ -0,0 +1,96 @@
+package shorter
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/InsideGallery/brf.im/handler/pages"
+	"github.com/InsideGallery/core/server/template"
+)
+
+var ErrInvalidRedirectMode error = errors.New("error invalid redirect mode")
+
+const (
+	RedirectMovedPermanently = "301"
+	RedirectFound            = "302"
+	RedirectTemporary        = "307"
+	RedirectPermanent        = "308"
+	// RedirectMeta render page with meta refresh
+	RedirectMeta = "meta"
+	// RedirectJS render page which redirect by javascript
+	RedirectJS = "js"
+)
+
+var redirectStatuses = map[string]int{
+	RedirectMovedPermanently: http.StatusMovedPermanently,
+	RedirectFound:            http.StatusFound,
+	RedirectTemporary:        http.StatusTemporaryRedirect,
+	RedirectPermanent:        http.StatusPermanentRedirect,
+}
+
+var defaultRedirectMode = GetRedirectModeFromEnv()
+
+// GetRedirectModeFromEnv return deployment default redirect mode, 308 when REDIRECT_MODE is not set or invalid
+func GetRedirectModeFromEnv() string {
+	mode := os.Getenv("REDIRECT_MODE")
+	if mode == "" {
+		return RedirectPermanent
+	}
+
+	if ValidateRedirectMode(mode) != nil {
+		slog.Warn("Invalid redirect mode, using default", "mode", mode, "default", RedirectPermanent)
+		return RedirectPermanent
+	}
+
+	return mode
+}
+
+// ValidateRedirectMode check redirect mode is known, empty mode mean deployment default
+func ValidateRedirectMode(mode string) error {
+	if _, ok := redirectStatuses[mode]; ok || mode == "" || mode == RedirectMeta || mode == RedirectJS {
+		return nil
+	}
+
+	return ErrInvalidRedirectMode
+}
+
+// GetRedirectMode return redirect mode of short url or deployment default
+func (m *ShortURLModel) GetRedirectMode() string {
+	if m.RedirectMode == "" {
+		return defaultRedirectMode
+	}
+
+	return m.RedirectMode
+}
+
+// Redirect send visitor to location by redirect mode, meta and js modes are rendered by redirect template,
+// location must be http or https url, links stored before urls were validated could have any scheme
+func Redirect(c *fiber.Ctx, tmpl *template.Engine, mode, location string) error {
+	if !isHTTPLocation(location) {
+		return refuseLocation(c, location)
+	}
+
+	if status, ok := redirectStatuses[mode]; ok {
+		return c.Redirect(location, status)
+	}
+
+	pg := pages.NewPage("Redirecting | Brief I am", ``, ``, ``, ``, ``, `Redirecting to`)
+	pg.Add("mode", mode)
+	pg.Add("url", location)
+
+	return pages.Render(c, tmpl, pages.RedirectTemplate, http.StatusOK, pg)
+}
+
+// refuseLocation respond with error instead of sending visitor to location, which is not http or https url
+func refuseLocation(c *fiber.Ctx, location string) error {
+	slog.Error("Error destination is not http url", "location", location)
+
+	c.Status(http.StatusInternalServerError)
+	_, err := c.WriteString("Error destination is invalid")
+
+	return err
+}
@@ -0,0 +1,116 @@
+package shorter
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	ErrReservedShortID error = errors.New("short id is reserved")
+	ErrInvalidShortID  error = errors.New("short id contains forbidden characters")
+)
+
+const idSeparators = "-_.~"
+
+// defaultReservedWords well-known paths requested by browsers and crawlers
+var defaultReservedWords = []string{"favicon.ico", "robots.txt", "sitemap.xml", ".well-known"}
+
+// ReservedWords registry of words which could not be used as short id or prefix
+type ReservedWords struct {
+	mu    sync.RWMutex
+	words map[string]struct{}
+}
+
+// NewReservedWords return registry with default words
+func NewReservedWords(words ...string) *ReservedWords {
+	r := &ReservedWords{
+		words: map[string]struct{}{},
+	}
+	r.Add(defaultReservedWords...)
+	r.Add(words...)
+
+	return r
+}
+
+// GetReservedWordsFromEnv return words listed in RESERVED_WORDS separated by comma
+func GetReservedWordsFromEnv() []string {
+	var words []string
+
+	for _, word := range strings.Split(os.Getenv("RESERVED_WORDS"), ",") {
+		word = strings.TrimSpace(word)
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// Add add words to registry, words are case-insensitive
+func (r *ReservedWords) Add(words ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, word := range words {
+		r.words[strings.ToLower(word)] = struct{}{}
+	}
+}
+
+// AddRoutes add first static segment of every route to registry
+func (r *ReservedWords) AddRoutes(routes []fiber.Route) {
+	for _, route := range routes {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(route.Path, "/"), "/")
+		if segment == "" || strings.ContainsAny(segment[:1], ":*+") {
+			continue
+		}
+
+		r.Add(segment)
+	}
+}
+
+// IsReserved return true if word is reserved
+func (r *ReservedWords) IsReserved(word string) bool {
+	r.mu.RLock()
+	_, exists := r.words[strings.ToLower(word)]
+	r.mu.RUnlock()
+
+	return exists
+}
+
+// Check return error if short id or prefix is reserved or break character policy
+func (r *ReservedWords) Check(shortID string) error {
+	err := ValidateShortID(shortID)
+	if err != nil {
+		return err
+	}
+
+	if r.IsReserved(shortID) {
+		return ErrReservedShortID
+	}
+
+	return nil
+}
+
+// ValidateShortID check short id or prefix contains only URL-safe characters
+// (letters, digits and `-_.~`) and does not start or end with separator
+func ValidateShortID(shortID string) error {
+	if shortID == "" {
+		return ErrInvalidShortID
+	}
+
+	for _, r := range shortID {
+		if !isAlphanumeric(r) && !strings.ContainsRune(idSeparators, r) {
+			return ErrInvalidShortID
+		}
+	}
+
+	if strings.ContainsAny(shortID[:1]+shortID[len(shortID)-1:], idSeparators) {
+		return ErrInvalidShortID
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package shorter
+
+import (
+	"errors"
+	"time"
+	_ "time/tzdata" // runtime image has no zoneinfo
+)
+
+var ErrInvalidSchedule error = errors.New("error invalid schedule")
+
+const (
+	LinkStateScheduled = "scheduled"
+	LinkStateLive      = "live"
+	LinkStateEnded     = "ended"
+)
+
+// localTimeLayouts layouts of times without offset, they are read in schedule time zone
+var localTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// ScheduleRequest describe activation window of link, times could be RFC 3339 with offset
+// or local (like `2030-01-01T10:00`) in IANA time zone (like `Europe/Kyiv`, default UTC)
+type ScheduleRequest struct {
+	NotBefore string `json:"notBefore"`
+	NotAfter  string `json:"notAfter"`
+	Timezone  string `json:"timezone"`
+}
+
+// Schedule return parsed window in UTC and normalized time zone name
+func (req ScheduleRequest) Schedule() (notBefore, notAfter *time.Time, timezone string, err error) {
+	loc := time.UTC
+
+	if req.Timezone != "" {
+		loc, err = time.LoadLocation(req.Timezone)
+		if err != nil {
+			return nil, nil, "", errors.Join(ErrInvalidSchedule, err)
+		}
+
+		timezone = loc.String()
+	}
+
+	notBefore, err = parseScheduleTime(req.NotBefore, loc)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	notAfter, err = parseScheduleTime(req.NotAfter, loc)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if notBefore != nil && notAfter != nil && !notAfter.After(*notBefore) {
+		return nil, nil, "", ErrInvalidSchedule
+	}
+
+	return notBefore, notAfter, timezone, nil
+}
+
+func parseScheduleTime(value string, loc *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+
+	for _, layout := range localTimeLayouts {
+		t, err = time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+
+	return nil, errors.Join(ErrInvalidSchedule, err)
+}
+
+// State return scheduled, live or ended state of short url at given time
+func (m *ShortURLModel) State(now time.Time) string {
+	switch {
+	case m.NotBefore != nil && now.Before(*m.NotBefore):
+		return LinkStateScheduled
+	case m.IsEnded(now):
+		return LinkStateEnded
+	}
+
+	return LinkStateLive
+}
+
+// IsEnded return true if activation window is over or short url is expired
+func (m *ShortURLModel) IsEnded(now time.Time) bool {
+	return (m.NotAfter != nil && !now.Before(*m.NotAfter)) || m.IsExpired(now)
+}
+
+// Location return time zone of short url schedule
+func (m *ShortURLModel) Location() *time.Location {
+	if m.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(m.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
@@ -0,0 +1,137 @@
+package shorter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestScheduleRequest(t *testing.T) {
+	at := func(value string) *time.Time {
+		t, _ := time.Parse(time.RFC3339, value)
+		return &t
+	}
+
+	cases := []struct {
+		name      string
+		req       ScheduleRequest
+		notBefore *time.Time
+		notAfter  *time.Time
+		timezone  string
+		err       error
+	}{
+		{name: "empty"},
+		{
+			name:      "rfc 3339 with offset",
+			req:       ScheduleRequest{NotBefore: "2030-01-01T10:00:00+02:00", NotAfter: "2030-01-02T10:00:00Z"},
+			notBefore: at("2030-01-01T08:00:00Z"),
+			notAfter:  at("2030-01-02T10:00:00Z"),
+		},
+		{
+			name:      "local time in utc by default",
+			req:       ScheduleRequest{NotBefore: "2030-01-01T10:00"},
+			notBefore: at("2030-01-01T10:00:00Z"),
+		},
+		{
+			name:      "local time in winter zone",
+			req:       ScheduleRequest{NotBefore: "2030-01-01T10:00", Timezone: "Europe/Kyiv"},
+			notBefore: at("2030-01-01T08:00:00Z"),
+			timezone:  "Europe/Kyiv",
+		},
+		{
+			name:     "local time in summer zone",
+			req:      ScheduleRequest{NotAfter: "2030-07-01 10:00:30", Timezone: "Europe/Kyiv"},
+			notAfter: at("2030-07-01T07:00:30Z"),
+			timezone: "Europe/Kyiv",
+		},
+		{
+			name: "local time in zone west of utc",
+			req: ScheduleRequest{
+				NotBefore: "2030-01-01 23:30",
+				NotAfter:  "2030-01-02T01:00:00",
+				Timezone:  "America/New_York",
+			},
+			notBefore: at("2030-01-02T04:30:00Z"),
+			notAfter:  at("2030-01-02T06:00:00Z"),
+			timezone:  "America/New_York",
+		},
+		{
+			name:      "offset wins over zone",
+			req:       ScheduleRequest{NotBefore: "2030-01-01T10:00:00Z", Timezone: "Europe/Kyiv"},
+			notBefore: at("2030-01-01T10:00:00Z"),
+			timezone:  "Europe/Kyiv",
+		},
+		{name: "unknown zone", req: ScheduleRequest{NotBefore: "2030-01-01T10:00", Timezone: "Mars/Olympus"}, err: ErrInvalidSchedule},
+		{name: "invalid time", req: ScheduleRequest{NotBefore: "tomorrow"}, err: ErrInvalidSchedule},
+		{
+			name: "end equal start",
+			req:  ScheduleRequest{NotBefore: "2030-01-01T10:00", NotAfter: "2030-01-01T08:00:00Z", Timezone: "Europe/Kyiv"},
+			err:  ErrInvalidSchedule,
+		},
+		{
+			name: "end before start",
+			req:  ScheduleRequest{NotBefore: "2030-01-02T10:00", NotAfter: "2030-01-01T10:00"},
+			err:  ErrInvalidSchedule,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			notBefore, notAfter, timezone, err := tc.req.Schedule()
+			testutils.Equal(t, errors.Is(err, tc.err), true)
+			testutils.Equal(t, notBefore, tc.notBefore)
+			testutils.Equal(t, notAfter, tc.notAfter)
+			testutils.Equal(t, timezone, tc.timezone)
+		})
+	}
+}
+
+func TestShortURLState(t *testing.T) {
+	notBefore := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(24 * time.Hour)
+	expiresAt := notBefore.Add(time.Hour)
+
+	cases := []struct {
+		name  string
+		model ShortURLModel
+		now   time.Time
+		want  string
+	}{
+		{name: "no schedule", now: notBefore, want: LinkStateLive},
+		{name: "before start", model: ShortURLModel{NotBefore: &notBefore}, now: notBefore.Add(-time.Second), want: LinkStateScheduled},
+		{name: "at start", model: ShortURLModel{NotBefore: &notBefore}, now: notBefore, want: LinkStateLive},
+		{
+			name:  "inside window",
+			model: ShortURLModel{NotBefore: &notBefore, NotAfter: &notAfter},
+			now:   notBefore.Add(time.Hour),
+			want:  LinkStateLive,
+		},
+		{
+			name:  "before end",
+			model: ShortURLModel{NotBefore: &notBefore, NotAfter: &notAfter},
+			now:   notAfter.Add(-time.Second),
+			want:  LinkStateLive,
+		},
+		{name: "at end", model: ShortURLModel{NotAfter: &notAfter}, now: notAfter, want: LinkStateEnded},
+		{
+			name:  "after end",
+			model: ShortURLModel{NotBefore: &notBefore, NotAfter: &notAfter},
+			now:   notAfter.Add(time.Hour),
+			want:  LinkStateEnded,
+		},
+		{
+			name:  "expired inside window",
+			model: ShortURLModel{NotBefore: &notBefore, NotAfter: &notAfter, ExpiresAt: &expiresAt},
+			now:   expiresAt,
+			want:  LinkStateEnded,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.Equal(t, tc.model.State(tc.now), tc.want)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package shorter
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidStatus error = errors.New("error invalid status")
+	ErrBlocked       error = errors.New("short url is blocked")
+)
+
+const (
+	StatusActive  = "active"
+	StatusPaused  = "paused"
+	StatusBlocked = "blocked"
+
+	maxStatusMessageLength = 500
+)
+
+// StatusRequest describe change of link status with optional message shown to visitors
+type StatusRequest struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Validate check status is known, blocked status could be set only by admin
+func (req StatusRequest) Validate(admin bool) error {
+	switch req.Status {
+	case StatusActive, StatusPaused:
+	case StatusBlocked:
+		if !admin {
+			return ErrInvalidStatus
+		}
+	default:
+		return ErrInvalidStatus
+	}
+
+	if utf8.RuneCountInString(req.Message) > maxStatusMessageLength {
+		return ErrInvalidStatus
+	}
+
+	return nil
+}
+
+// Apply set status of short url, active status clear message
+func (req StatusRequest) Apply(model *ShortURLModel) {
+	model.Status = req.Status
+	model.StatusMessage = req.Message
+
+	if req.Status == StatusActive {
+		model.Status = ""
+		model.StatusMessage = ""
+	}
+}
+
+// GetStatus return status of short url, links without status are active
+func (m *ShortURLModel) GetStatus() string {
+	if m.Status == "" {
+		return StatusActive
+	}
+
+	return m.Status
+}
@@ -0,0 +1,62 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestUpdateShortURLStatus(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			owner := primitive.NewObjectID()
+
+			model := &ShortURLModel{ShortID: "status", Owner: owner, URL: "https://example.com"}
+			testutils.Equal(t, store.InsertShortURL(ctx, model), nil)
+
+			// owner read link before it is blocked
+			stale, err := store.GetShortURL(ctx, model.ShortID, owner)
+			testutils.Equal(t, err, nil)
+
+			blocked := &ShortURLModel{ShortID: model.ShortID, Status: StatusBlocked, StatusMessage: "abuse"}
+			updated, err := store.UpdateShortURLStatus(ctx, blocked, true)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, updated.Status, StatusBlocked)
+			testutils.Equal(t, updated.URL, model.URL)
+
+			// full update of stale link must keep block
+			stale.Title = "title"
+			testutils.Equal(t, store.UpdateShortURL(ctx, stale), nil)
+
+			current, err := store.ResolveShortURL(ctx, model.ShortID)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, current.Status, StatusBlocked)
+			testutils.Equal(t, current.StatusMessage, "abuse")
+			testutils.Equal(t, current.Title, "title")
+
+			paused := &ShortURLModel{ShortID: model.ShortID, Owner: owner, Status: StatusPaused}
+			_, err = store.UpdateShortURLStatus(ctx, paused, false)
+			testutils.Equal(t, errors.Is(err, ErrBlocked), true)
+
+			other := &ShortURLModel{ShortID: model.ShortID, Owner: primitive.NewObjectID(), Status: StatusPaused}
+			_, err = store.UpdateShortURLStatus(ctx, other, false)
+			testutils.Equal(t, errors.Is(err, ErrNotFound), true)
+
+			active := &ShortURLModel{ShortID: model.ShortID, UpdatedAt: time.Now().UTC().Truncate(time.Millisecond)}
+			_, err = store.UpdateShortURLStatus(ctx, active, true)
+			testutils.Equal(t, err, nil)
+
+			updated, err = store.UpdateShortURLStatus(ctx, paused, false)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, updated.GetStatus(), StatusPaused)
+			testutils.Equal(t, updated.StatusMessage, "")
+			testutils.Equal(t, updated.Title, "title")
+		})
+	}
+}
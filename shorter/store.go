@@ -0,0 +1,44 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrNotFound      error = errors.New("not found")
+	ErrShortIDExists error = errors.New("short id already exists")
+)
+
+// Store describe storage of owners, short urls and click counters.
+// InsertShortURL must return ErrShortIDExists when short id is already taken,
+// UpdateShortURL replace mutable fields of short url found by short id and owner, and keep click counter and status.
+// UpdateShortURLStatus atomically set status, status message and update time of short url and return updated one,
+// admin update find short url by short id only, owner update find it by short id and owner
+// and must return ErrBlocked when short url is blocked.
+// Versions are ordered from oldest to newest and are removed together with short url.
+// IncrementClicks must atomically check max clicks and increment counter (and counter of variant, when given),
+// it return false when short url is not found or its limit is already reached.
+type Store interface {
+	Sequencer
+	CreateOwner(ctx context.Context, model *OwnerModel) (primitive.ObjectID, error)
+	GetOwner(ctx context.Context, id primitive.ObjectID) (*OwnerModel, error)
+	UpdateOwner(ctx context.Context, model *OwnerModel) error
+	RemoveOwner(ctx context.Context, id primitive.ObjectID) error
+	InsertShortURL(ctx context.Context, model *ShortURLModel) error
+	RemoveShortURL(ctx context.Context, shortID string, owner primitive.ObjectID) error
+	GetShortURLs(ctx context.Context, owner primitive.ObjectID, filter ShortURLFilter) ([]ShortURLModel, error)
+	GetShortURL(ctx context.Context, shortID string, owner primitive.ObjectID) (*ShortURLModel, error)
+	ResolveShortURL(ctx context.Context, shortID string) (*ShortURLModel, error)
+	UpdateShortURL(ctx context.Context, model *ShortURLModel) error
+	UpdateShortURLStatus(ctx context.Context, model *ShortURLModel, admin bool) (*ShortURLModel, error)
+	PurgeExpired(ctx context.Context, before time.Time) ([]string, error)
+	ShortIDs(ctx context.Context, fn func(shortID string) error) error
+	IncrementClicks(ctx context.Context, shortID, variant string) (bool, error)
+	AddVersion(ctx context.Context, model *VersionModel) error
+	GetVersions(ctx context.Context, shortID string) ([]VersionModel, error)
+	GetVersion(ctx context.Context, shortID string, id primitive.ObjectID) (*VersionModel, error)
+}
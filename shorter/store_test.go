@@ -0,0 +1,48 @@
+package shorter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/core/db/mongodb"
+	"github.com/InsideGallery/core/testutils"
+)
+
+// testStores return stores used by tests, mongo store is used only when MONGO_HOSTS is set
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+	}
+
+	boltStore, err := NewBoltStore(filepath.Join(t.TempDir(), "test.db"))
+	testutils.Equal(t, err, nil)
+	t.Cleanup(func() { _ = boltStore.Close() })
+
+	stores["bolt"] = boltStore
+
+	if os.Getenv("MONGO_HOSTS") == "" {
+		return stores
+	}
+
+	config, err := mongodb.GetConnectionConfigFromEnv()
+	testutils.Equal(t, err, nil)
+
+	config.Database = "brfim_test_" + primitive.NewObjectID().Hex()
+
+	client, err := mongodb.NewMongoClient(config)
+	testutils.Equal(t, err, nil)
+	t.Cleanup(func() { _ = client.Database(config.Database).Drop(context.Background()) })
+
+	mongoStore := NewMongoStore(client)
+	testutils.Equal(t, mongoStore.EnsureIndexes(context.Background()), nil)
+
+	stores["mongo"] = mongoStore
+
+	return stores
+}
@@ -0,0 +1,142 @@
+package shorter
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidVariants error = errors.New("error invalid variants")
+
+const (
+	VariantCookie = "brfim_variant"
+
+	maxVariants          = 10
+	maxVariantNameLength = 32
+	maxVariantWeight     = 1000
+	variantCookieTTL     = 30 * 24 * time.Hour
+)
+
+// Variant weighted destination of link, visitors are split between variants proportionally to weight
+type Variant struct {
+	Name   string `bson:"name" json:"name"`
+	URL    string `bson:"url" json:"url"`
+	Weight int    `bson:"weight" json:"weight"`
+}
+
+// ValidateVariants check variants have unique names (letters, digits, `-` and `_`),
+// valid urls and positive weights
+func ValidateVariants(variants []Variant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	if len(variants) < 2 || len(variants) > maxVariants {
+		return ErrInvalidVariants
+	}
+
+	names := map[string]struct{}{}
+
+	for _, variant := range variants {
+		if variant.Name == "" || len(variant.Name) > maxVariantNameLength {
+			return ErrInvalidVariants
+		}
+
+		for _, r := range variant.Name {
+			if !isAlphanumeric(r) && r != '-' && r != '_' {
+				return ErrInvalidVariants
+			}
+		}
+
+		if _, ok := names[variant.Name]; ok {
+			return ErrInvalidVariants
+		}
+
+		names[variant.Name] = struct{}{}
+
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight || ValidateURL(variant.URL) != nil {
+			return ErrInvalidVariants
+		}
+	}
+
+	return nil
+}
+
+// GetVariant return variant by name
+func (m *ShortURLModel) GetVariant(name string) (Variant, bool) {
+	for _, variant := range m.Variants {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+
+	return Variant{}, false
+}
+
+// PickVariant return random variant chosen by weights, empty name when link has no variants
+func (m *ShortURLModel) PickVariant() string {
+	var total int
+	for _, variant := range m.Variants {
+		total += variant.Weight
+	}
+
+	if total == 0 {
+		return ""
+	}
+
+	n := rand.IntN(total) //nolint:gosec
+	for _, variant := range m.Variants {
+		if n < variant.Weight {
+			return variant.Name
+		}
+
+		n -= variant.Weight
+	}
+
+	return ""
+}
+
+// ChooseVariant return variant for visitor, sticky links keep variant in cookie,
+// so returning visitor see the same variant
+func ChooseVariant(c *fiber.Ctx, shortURL *ShortURLModel) string {
+	if len(shortURL.Variants) == 0 {
+		return ""
+	}
+
+	if shortURL.Sticky {
+		if variant, ok := shortURL.GetVariant(c.Cookies(VariantCookie)); ok {
+			return variant.Name
+		}
+	}
+
+	name := shortURL.PickVariant()
+
+	if shortURL.Sticky {
+		c.Cookie(&fiber.Cookie{
+			Name:     VariantCookie,
+			Value:    name,
+			Path:     "/" + shortURL.ShortID,
+			Expires:  time.Now().Add(variantCookieTTL),
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteLaxMode,
+		})
+	}
+
+	return name
+}
+
+// incrementVariantClicks return copy of counters with incremented counter of variant,
+// copy keep models returned earlier unchanged
+func incrementVariantClicks(clicks map[string]int64, variant string) map[string]int64 {
+	result := make(map[string]int64, len(clicks)+1)
+	for name, n := range clicks {
+		result[name] = n
+	}
+
+	result[strings.Clone(variant)]++
+
+	return result
+}
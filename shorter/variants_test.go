@@ -0,0 +1,172 @@
+package shorter
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestPickVariant(t *testing.T) {
+	const n = 40000
+
+	model := &ShortURLModel{Variants: []Variant{
+		{Name: "a", URL: "https://a.example.com", Weight: 3},
+		{Name: "b", URL: "https://b.example.com", Weight: 1},
+		{Name: "c", URL: "https://c.example.com", Weight: 4},
+	}}
+
+	picked := map[string]int{}
+	for range n {
+		picked[model.PickVariant()]++
+	}
+
+	testutils.Equal(t, len(picked), 3)
+
+	for name, weight := range map[string]int{"a": 3, "b": 1, "c": 4} {
+		share := float64(picked[name]) / n
+		testutils.Equal(t, math.Abs(share-float64(weight)/8) < 0.02, true)
+	}
+
+	testutils.Equal(t, (&ShortURLModel{}).PickVariant(), "")
+}
+
+// chooseVariant return variant chosen for request with given variant cookie and Set-Cookie header of response
+func chooseVariant(t *testing.T, model *ShortURLModel, cookie string) (string, string) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/:shortID", func(c *fiber.Ctx) error {
+		return c.SendString(ChooseVariant(c, model))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/"+model.ShortID, nil)
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: VariantCookie, Value: cookie})
+	}
+
+	resp, err := app.Test(req, -1)
+	testutils.Equal(t, err, nil)
+
+	body, err := io.ReadAll(resp.Body)
+	testutils.Equal(t, err, nil)
+
+	return string(body), resp.Header.Get(fiber.HeaderSetCookie)
+}
+
+func TestChooseVariant(t *testing.T) {
+	variants := []Variant{
+		{Name: "a", URL: "https://a.example.com", Weight: 1},
+		// b is never picked randomly, so it could be chosen only by cookie
+		{Name: "b", URL: "https://b.example.com", Weight: 0},
+	}
+
+	sticky := &ShortURLModel{ShortID: "sticky", Variants: variants, Sticky: true}
+
+	name, cookie := chooseVariant(t, sticky, "")
+	testutils.Equal(t, name, "a")
+	testutils.Equal(t, cookie != "", true)
+
+	cookies, err := http.ParseSetCookie(cookie)
+	testutils.Equal(t, err, nil)
+	testutils.Equal(t, cookies.Name, VariantCookie)
+	testutils.Equal(t, cookies.Value, "a")
+	testutils.Equal(t, cookies.Path, "/sticky")
+	testutils.Equal(t, cookies.HttpOnly, true)
+
+	// returning visitor keep variant and cookie is not set again
+	name, cookie = chooseVariant(t, sticky, "b")
+	testutils.Equal(t, name, "b")
+	testutils.Equal(t, cookie, "")
+
+	// cookie of unknown variant (removed or forged) is replaced
+	for _, value := range []string{"removed", "<script>", "A"} {
+		name, cookie = chooseVariant(t, sticky, value)
+		testutils.Equal(t, name, "a")
+
+		cookies, err = http.ParseSetCookie(cookie)
+		testutils.Equal(t, err, nil)
+		testutils.Equal(t, cookies.Value, "a")
+	}
+
+	// cookie is ignored and not set by links which are not sticky
+	notSticky := &ShortURLModel{ShortID: "random", Variants: variants}
+
+	name, cookie = chooseVariant(t, notSticky, "b")
+	testutils.Equal(t, name, "a")
+	testutils.Equal(t, cookie, "")
+
+	name, cookie = chooseVariant(t, &ShortURLModel{ShortID: "plain", Sticky: true}, "a")
+	testutils.Equal(t, name, "")
+	testutils.Equal(t, cookie, "")
+}
+
+func TestIncrementVariantClicks(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			model := &ShortURLModel{
+				ShortID: "variants",
+				Owner:   primitive.NewObjectID(),
+				URL:     "https://example.com",
+				Variants: []Variant{
+					{Name: "a", URL: "https://a.example.com", Weight: 1},
+					{Name: "b", URL: "https://b.example.com", Weight: 1},
+				},
+				MaxClicks: 5,
+			}
+			testutils.Equal(t, store.InsertShortURL(ctx, model), nil)
+
+			before, err := store.ResolveShortURL(ctx, model.ShortID)
+			testutils.Equal(t, err, nil)
+
+			for _, variant := range []string{"a", "b", "a", ""} {
+				ok, err := store.IncrementClicks(ctx, model.ShortID, variant)
+				testutils.Equal(t, err, nil)
+				testutils.Equal(t, ok, true)
+			}
+
+			current, err := store.ResolveShortURL(ctx, model.ShortID)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, current.Clicks, int64(4))
+			testutils.Equal(t, current.VariantClicks, map[string]int64{"a": 2, "b": 1})
+
+			// model returned earlier is not changed
+			testutils.Equal(t, len(before.VariantClicks), 0)
+
+			ok, err := store.IncrementClicks(ctx, model.ShortID, "b")
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, ok, true)
+
+			// clicks over limit are not counted for variant too
+			ok, err = store.IncrementClicks(ctx, model.ShortID, "b")
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, ok, false)
+
+			current, err = store.ResolveShortURL(ctx, model.ShortID)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, current.Clicks, int64(5))
+			testutils.Equal(t, current.VariantClicks, map[string]int64{"a": 2, "b": 2})
+
+			// counters are kept by update of link
+			current.Title = "title"
+			testutils.Equal(t, store.UpdateShortURL(ctx, current), nil)
+
+			current, err = store.ResolveShortURL(ctx, model.ShortID)
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, current.VariantClicks, map[string]int64{"a": 2, "b": 2})
+
+			ok, err = store.IncrementClicks(ctx, "unknown", "a")
+			testutils.Equal(t, err, nil)
+			testutils.Equal(t, ok, false)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package shorter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/brf.im/rules"
+)
+
+var (
+	ErrInvalidURL       error = errors.New("error invalid url")
+	ErrInvalidMaxClicks error = errors.New("error invalid max clicks")
+)
+
+const (
+	ActorOwner = "owner"
+	ActorAdmin = "admin"
+
+	maxActorLength = 128
+)
+
+// VersionModel snapshot of short url saved on every change
+type VersionModel struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	ShortID   string             `bson:"short_id" json:"shortID"`
+	Owner     primitive.ObjectID `bson:"owner" json:"owner"`
+	Actor     string             `bson:"actor" json:"actor"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+	Link      ShortURLModel      `bson:"link" json:"link"`
+}
+
+// NewVersion return new version of short url made by actor
+func NewVersion(model *ShortURLModel, actor string) *VersionModel {
+	id := primitive.NewObjectID()
+
+	return &VersionModel{
+		ID:        id,
+		ShortID:   model.ShortID,
+		Owner:     model.Owner,
+		Actor:     actor,
+		CreatedAt: id.Timestamp().UTC(),
+		Link:      *model,
+	}
+}
+
+// SaveShortURL update short url and store its new version
+func SaveShortURL(ctx context.Context, store Store, model *ShortURLModel, actor string) error {
+	model.UpdatedAt = time.Now().UTC()
+
+	err := store.UpdateShortURL(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	return store.AddVersion(ctx, NewVersion(model, actor))
+}
+
+// SaveShortURLStatus atomically change status of short url and store its new version,
+// status is not part of full update, so concurrent owner update could not undo block by admin
+func SaveShortURLStatus(
+	ctx context.Context,
+	store Store,
+	model *ShortURLModel,
+	admin bool,
+	actor string,
+) (*ShortURLModel, error) {
+	model.UpdatedAt = time.Now().UTC()
+
+	updated, err := store.UpdateShortURLStatus(ctx, model, admin)
+	if err != nil {
+		return updated, err
+	}
+
+	return updated, store.AddVersion(ctx, NewVersion(updated, actor))
+}
+
+// Rollback return short url with content of given version, status and access control (password, expiration,
+// schedule and max clicks) of current short url are kept, so rollback could not unblock link or remove its protection
+func Rollback(current *ShortURLModel, version *VersionModel) *ShortURLModel {
+	model := version.Link
+	model.ShortID = current.ShortID
+	model.Owner = current.Owner
+	model.Status = current.Status
+	model.StatusMessage = current.StatusMessage
+	model.PasswordHash = current.PasswordHash
+	model.ExpiresAt = current.ExpiresAt
+	model.NotBefore = current.NotBefore
+	model.NotAfter = current.NotAfter
+	model.Timezone = current.Timezone
+	model.MaxClicks = current.MaxClicks
+	model.CreatedAt = current.CreatedAt
+	model.Clicks = current.Clicks
+	model.VariantClicks = current.VariantClicks
+
+	return &model
+}
+
+// GetActor return actor of owner request from X-Actor header
+func GetActor(c *fiber.Ctx) string {
+	actor := c.Get("X-Actor")
+	if actor == "" || len(actor) > maxActorLength {
+		return ActorOwner
+	}
+
+	return actor
+}
+
+// PatchShortURLRequest describe partial update of short url, only given fields are changed
+type PatchShortURLRequest struct {
+	URL       *string   `json:"url"`
+	MaxClicks *int64    `json:"maxClicks"`
+	Title     *string   `json:"title"`
+	Notes     *string   `json:"notes"`
+	Tags      *[]string `json:"tags"`
+	// Rules replace all routing rules, empty list remove them
+	Rules *[]rules.Rule `json:"rules"`
+	// Platforms replace all platform overrides, empty object remove them
+	Platforms *map[string]string `json:"platforms"`
+	// Countries replace all country overrides, empty object remove them
+	Countries *map[string]string `json:"countries"`
+	// Variants replace all variants, empty list remove them
+	Variants *[]Variant `json:"variants"`
+	Sticky   *bool      `json:"sticky"`
+	// QueryPolicy empty string restore default policy (append)
+	QueryPolicy     *string `json:"queryPolicy"`
+	PathPassthrough *bool   `json:"pathPassthrough"`
+	// RedirectMode empty string restore deployment default
+	RedirectMode *string `json:"redirectMode"`
+	// Pixels replace all pixels, empty list remove them
+	Pixels     *[]Pixel `json:"pixels"`
+	PixelDelay *int64   `json:"pixelDelay"`
+}
+
+// Apply change short url by request
+func (req PatchShortURLRequest) Apply(model *ShortURLModel) error {
+	if req.URL != nil {
+		err := ValidateURL(*req.URL)
+		if err != nil {
+			return err
+		}
+
+		model.URL = *req.URL
+	}
+
+	if req.Rules != nil {
+		err := ValidateRules(*req.Rules)
+		if err != nil {
+			return err
+		}
+
+		model.Rules = *req.Rules
+	}
+
+	if req.Platforms != nil {
+		err := ValidatePlatforms(*req.Platforms)
+		if err != nil {
+			return err
+		}
+
+		model.Platforms = *req.Platforms
+	}
+
+	if req.Countries != nil {
+		countries, err := NormalizeCountries(*req.Countries)
+		if err != nil {
+			return err
+		}
+
+		model.Countries = countries
+	}
+
+	if req.Variants != nil {
+		err := ValidateVariants(*req.Variants)
+		if err != nil {
+			return err
+		}
+
+		model.Variants = *req.Variants
+	}
+
+	if req.Sticky != nil {
+		model.Sticky = *req.Sticky
+	}
+
+	if req.QueryPolicy != nil {
+		err := ValidateQueryPolicy(*req.QueryPolicy)
+		if err != nil {
+			return err
+		}
+
+		model.QueryPolicy = *req.QueryPolicy
+	}
+
+	if req.PathPassthrough != nil {
+		model.PathPassthrough = *req.PathPassthrough
+	}
+
+	if req.RedirectMode != nil {
+		err := ValidateRedirectMode(*req.RedirectMode)
+		if err != nil {
+			return err
+		}
+
+		model.RedirectMode = *req.RedirectMode
+	}
+
+	if req.Pixels != nil || req.PixelDelay != nil {
+		pixels, delay := model.Pixels, model.PixelDelay
+		if req.Pixels != nil {
+			pixels = *req.Pixels
+		}
+
+		if req.PixelDelay != nil {
+			delay = *req.PixelDelay
+		}
+
+		err := ValidatePixels(pixels, delay)
+		if err != nil {
+			return err
+		}
+
+		model.Pixels, model.PixelDelay = pixels, delay
+	}
+
+	if req.MaxClicks != nil {
+		if *req.MaxClicks < 0 {
+			return ErrInvalidMaxClicks
+		}
+
+		model.MaxClicks = *req.MaxClicks
+	}
+
+	if req.Title != nil {
+		model.Title = *req.Title
+	}
+
+	if req.Notes != nil {
+		model.Notes = *req.Notes
+	}
+
+	if req.Tags != nil {
+		tags, err := NormalizeTags(*req.Tags)
+		if err != nil {
+			return err
+		}
+
+		model.Tags = tags
+	}
+
+	return ValidateMetadata(model.Title, model.Notes)
+}
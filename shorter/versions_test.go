@@ -0,0 +1,60 @@
+package shorter
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestRollback(t *testing.T) {
+	owner := primitive.NewObjectID()
+	expiresAt := time.Now().Add(time.Hour)
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(24 * time.Hour)
+	oldNotBefore := time.Now().Add(-48 * time.Hour)
+	oldNotAfter := time.Now().Add(-24 * time.Hour)
+
+	version := &VersionModel{Link: ShortURLModel{
+		ShortID: "old",
+		URL:     "https://example.com/old",
+		Title:   "old",
+		// window of old version is over, restoring it would end live link
+		NotBefore: &oldNotBefore,
+		NotAfter:  &oldNotAfter,
+		Timezone:  "Europe/Kyiv",
+	}}
+	current := &ShortURLModel{
+		ShortID:       "link",
+		Owner:         owner,
+		URL:           "https://example.com/new",
+		Title:         "new",
+		Status:        StatusBlocked,
+		StatusMessage: "abuse",
+		PasswordHash:  "hash",
+		ExpiresAt:     &expiresAt,
+		NotBefore:     &notBefore,
+		NotAfter:      &notAfter,
+		MaxClicks:     10,
+		Clicks:        5,
+	}
+
+	model := Rollback(current, version)
+
+	testutils.Equal(t, model.URL, "https://example.com/old")
+	testutils.Equal(t, model.Title, "old")
+	testutils.Equal(t, model.ShortID, "link")
+	testutils.Equal(t, model.Owner, owner)
+	testutils.Equal(t, model.Status, StatusBlocked)
+	testutils.Equal(t, model.StatusMessage, "abuse")
+	testutils.Equal(t, model.PasswordHash, "hash")
+	testutils.Equal(t, model.ExpiresAt, &expiresAt)
+	testutils.Equal(t, model.NotBefore, &notBefore)
+	testutils.Equal(t, model.NotAfter, &notAfter)
+	testutils.Equal(t, model.Timezone, "")
+	testutils.Equal(t, model.State(time.Now()), LinkStateLive)
+	testutils.Equal(t, model.MaxClicks, int64(10))
+	testutils.Equal(t, model.Clicks, int64(5))
+}
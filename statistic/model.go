@@ -4,37 +4,17 @@ import (
 	"context"
 
 	"github.com/InsideGallery/brf.im/shorter"
-	"go.mongodb.org/mongo-driver/bson"
-
-	"github.com/InsideGallery/core/db/mongodb"
 )
 
 type Statistic struct {
-	client *mongodb.MongoClient
+	store shorter.Store
 }
 
-func New() (*Statistic, error) {
-	db, err := mongodb.Default()
-	if err != nil {
-		return nil, err
-	}
-
-	return &Statistic{client: db}, nil
+func New(store shorter.Store) *Statistic {
+	return &Statistic{store: store}
 }
 
-func (s *Statistic) Track(ctx context.Context, shortID string) error {
-	filter := bson.D{{Key: "short_id", Value: shortID}}
-
-	cnt, err := s.client.CountDocuments(ctx, shorter.CollectionShortURLs, filter)
-	if err != nil {
-		return err
-	}
-
-	if cnt == 0 {
-		return nil
-	}
-
-	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "clicks", Value: 1}}}}
-
-	return s.client.UpsertOne(ctx, shorter.CollectionShortURLs, update, filter)
+// Track count click on short url and on its variant (when given), it return false when click limit is reached
+func (s *Statistic) Track(ctx context.Context, shortID, variant string) (bool, error) {
+	return s.store.IncrementClicks(ctx, shortID, variant)
 }
@@ -0,0 +1,98 @@
+package useragent
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	PlatformIOS      = "ios"
+	PlatformAndroid  = "android"
+	PlatformWindows  = "windows"
+	PlatformMacOS    = "macos"
+	PlatformLinux    = "linux"
+	PlatformChromeOS = "chromeos"
+	PlatformOther    = "other"
+
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+// Platforms every platform Classify could return
+var Platforms = []string{
+	PlatformIOS, PlatformAndroid, PlatformWindows, PlatformMacOS, PlatformLinux, PlatformChromeOS, PlatformOther,
+}
+
+// Devices every device class Classify could return
+var Devices = []string{DeviceMobile, DeviceTablet, DeviceDesktop, DeviceBot}
+
+// botMarkers explicit tokens of crawlers and link preview fetchers, bare "bot" or "preview" would match
+// real devices (like CUBOT phones), crawlers not listed here are usually detected by their info url (`+http`)
+var botMarkers = []string{
+	"googlebot", "bingbot", "yandexbot", "duckduckbot", "baiduspider", "applebot", "petalbot", "ahrefsbot",
+	"semrushbot", "mj12bot", "dotbot", "twitterbot", "linkedinbot", "pinterestbot", "redditbot", "slackbot",
+	"discordbot", "telegrambot", "whatsapp/", "skypeuripreview", "facebookexternalhit", "facebookcatalog",
+	"embedly", "crawler", "spider", "slurp", "+http", "curl/", "wget/", "python-requests/", "go-http-client/",
+	"headlesschrome",
+}
+
+// Client describe platform and device class of client
+type Client struct {
+	Platform string
+	Device   string
+}
+
+// Classify return platform and device class detected from User-Agent header
+func Classify(userAgent string) Client {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case ua == "":
+		return Client{Platform: PlatformOther, Device: DeviceDesktop}
+	case containsAny(ua, botMarkers...):
+		return Client{Platform: PlatformOther, Device: DeviceBot}
+	// windows phone pretend to be android or iphone
+	case strings.Contains(ua, "windows phone"):
+		return Client{Platform: PlatformOther, Device: DeviceMobile}
+	case strings.Contains(ua, "ipad"):
+		return Client{Platform: PlatformIOS, Device: DeviceTablet}
+	case containsAny(ua, "iphone", "ipod"):
+		return Client{Platform: PlatformIOS, Device: DeviceMobile}
+	case strings.Contains(ua, "android"):
+		// android tablets do not send Mobile token
+		if strings.Contains(ua, "mobile") {
+			return Client{Platform: PlatformAndroid, Device: DeviceMobile}
+		}
+
+		return Client{Platform: PlatformAndroid, Device: DeviceTablet}
+	case strings.Contains(ua, "windows"):
+		return Client{Platform: PlatformWindows, Device: DeviceDesktop}
+	case strings.Contains(ua, "cros"):
+		return Client{Platform: PlatformChromeOS, Device: DeviceDesktop}
+	case containsAny(ua, "macintosh", "mac os x"):
+		return Client{Platform: PlatformMacOS, Device: DeviceDesktop}
+	case containsAny(ua, "linux", "x11"):
+		return Client{Platform: PlatformLinux, Device: DeviceDesktop}
+	case containsAny(ua, "mobile", "opera mini"):
+		return Client{Platform: PlatformOther, Device: DeviceMobile}
+	}
+
+	return Client{Platform: PlatformOther, Device: DeviceDesktop}
+}
+
+// IsKnownKey return true if key is platform or device class
+func IsKnownKey(key string) bool {
+	return slices.Contains(Platforms, key) || slices.Contains(Devices, key)
+}
+
+func containsAny(s string, substrings ...string) bool {
+	for _, substr := range substrings {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+
+	return false
+}
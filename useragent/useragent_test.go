@@ -0,0 +1,180 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/InsideGallery/core/testutils"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name      string
+		userAgent string
+		want      Client
+	}{
+		{
+			name:      "iphone safari",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			want:      Client{Platform: PlatformIOS, Device: DeviceMobile},
+		},
+		{
+			name:      "ipod",
+			userAgent: "Mozilla/5.0 (iPod touch; CPU iPhone OS 15_7 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.6 Mobile/15E148 Safari/604.1",
+			want:      Client{Platform: PlatformIOS, Device: DeviceMobile},
+		},
+		{
+			name:      "ipad safari",
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1",
+			want:      Client{Platform: PlatformIOS, Device: DeviceTablet},
+		},
+		{
+			name:      "android phone chrome",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			want:      Client{Platform: PlatformAndroid, Device: DeviceMobile},
+		},
+		{
+			name:      "android tablet chrome",
+			userAgent: "Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Safari/537.36",
+			want:      Client{Platform: PlatformAndroid, Device: DeviceTablet},
+		},
+		{
+			name:      "windows chrome",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want:      Client{Platform: PlatformWindows, Device: DeviceDesktop},
+		},
+		{
+			name:      "windows phone",
+			userAgent: "Mozilla/5.0 (Windows Phone 10.0; Android 6.0.1; Microsoft; Lumia 950) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/52.0.2743.116 Mobile Safari/537.36 Edge/15.15063",
+			want:      Client{Platform: PlatformOther, Device: DeviceMobile},
+		},
+		{
+			name:      "windows phone 8.1",
+			userAgent: "Mozilla/5.0 (Mobile; Windows Phone 8.1; Android 4.0; ARM; Trident/7.0; Touch; rv:11.0; IEMobile/11.0; NOKIA; Lumia 635) like iPhone OS 7_0_3 Mac OS X AppleWebKit/537 (KHTML, like Gecko) Mobile Safari/537",
+			want:      Client{Platform: PlatformOther, Device: DeviceMobile},
+		},
+		{
+			name:      "macos safari",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			want:      Client{Platform: PlatformMacOS, Device: DeviceDesktop},
+		},
+		{
+			name:      "linux firefox",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want:      Client{Platform: PlatformLinux, Device: DeviceDesktop},
+		},
+		{
+			name:      "chromeos chrome",
+			userAgent: "Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want:      Client{Platform: PlatformChromeOS, Device: DeviceDesktop},
+		},
+		{
+			name:      "opera mini",
+			userAgent: "Opera/9.80 (J2ME/MIDP; Opera Mini/9.80 (S60; SymbOS; Opera Mobi/23.348; U; en) Presto/2.5.25 Version/10.54",
+			want:      Client{Platform: PlatformOther, Device: DeviceMobile},
+		},
+		{
+			name:      "googlebot",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "googlebot smartphone",
+			userAgent: "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "facebook crawler",
+			userAgent: "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "bingbot",
+			userAgent: "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm) Chrome/124.0.6367.82 Safari/537.36",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "slack link preview",
+			userAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "whatsapp link preview",
+			userAgent: "WhatsApp/2.23.20.0 A",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "skype link preview",
+			userAgent: "Mozilla/5.0 (Windows NT 6.1; WOW64) SkypeUriPreview Preview/0.5",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "unlisted crawler with info url",
+			userAgent: "Mozilla/5.0 (compatible; SomeIndexer/1.0; +https://indexer.example.com/about)",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "headless chrome",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/124.0.6367.82 Safari/537.36",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "python requests",
+			userAgent: "python-requests/2.31.0",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "cubot phone",
+			userAgent: "Mozilla/5.0 (Linux; Android 10; CUBOT_X30) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			want:      Client{Platform: PlatformAndroid, Device: DeviceMobile},
+		},
+		{
+			name:      "cubot phone with build",
+			userAgent: "Mozilla/5.0 (Linux; Android 12; CUBOT NOTE 20 PRO Build/SP1A.210812.016) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			want:      Client{Platform: PlatformAndroid, Device: DeviceMobile},
+		},
+		{
+			name:      "cubot tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 13; CUBOT TAB KINGKONG) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Safari/537.36",
+			want:      Client{Platform: PlatformAndroid, Device: DeviceTablet},
+		},
+		{
+			name:      "robot vacuum app webview",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 RoboticsHome/4.2",
+			want:      Client{Platform: PlatformIOS, Device: DeviceMobile},
+		},
+		{
+			name:      "browser preview build",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Safari/537.36 PreviewBrowser/1.0",
+			want:      Client{Platform: PlatformWindows, Device: DeviceDesktop},
+		},
+		{
+			name:      "curl",
+			userAgent: "curl/8.5.0",
+			want:      Client{Platform: PlatformOther, Device: DeviceBot},
+		},
+		{
+			name:      "empty",
+			userAgent: "",
+			want:      Client{Platform: PlatformOther, Device: DeviceDesktop},
+		},
+		{
+			name:      "unknown",
+			userAgent: "SomeClient/1.0",
+			want:      Client{Platform: PlatformOther, Device: DeviceDesktop},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.Equal(t, Classify(tc.userAgent), tc.want)
+		})
+	}
+}
+
+func TestIsKnownKey(t *testing.T) {
+	for _, key := range append(append([]string{}, Platforms...), Devices...) {
+		testutils.Equal(t, IsKnownKey(key), true)
+	}
+
+	testutils.Equal(t, IsKnownKey("symbian"), false)
+}